@@ -0,0 +1,215 @@
+// Package auth verify the JWT sent by drivers and riders.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+var (
+	// ErrMissingToken returned when the request doesn't carry any token.
+	ErrMissingToken = errors.New("missing token")
+	// ErrInvalidToken returned when the token can't be verified, is expired or has invalid claims.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrBusNotAllowed returned when the driver send location of a bus the token doesn't cover.
+	ErrBusNotAllowed = errors.New("bus not allowed")
+)
+
+// Config denotes the keys the token can be signed with.
+// At least one of HS256Secret or JWKSFile must be set.
+type Config struct {
+	// HS256Secret verify token signed with HS256.
+	HS256Secret []byte
+	// JWKSFile is the path of a JSON Web Key Set verifying token signed with RS256.
+	JWKSFile string
+	// Issuer and Audience are checked against the iss and aud claim when not empty.
+	Issuer   string
+	Audience string
+	// TenantRoutes is the routes the riders of every tenant can follow, in addition to the route_ids of their token.
+	// The tenant is matched case insensitively.
+	TenantRoutes map[string][]string
+}
+
+// Verifier verify the token and returns who it is issued to.
+type Verifier struct {
+	cfg     Config
+	keys    jwks
+	tenants map[string][]string
+}
+
+// NewVerifier create a verifier, the JWKS file is loaded once.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if len(cfg.HS256Secret) == 0 && cfg.JWKSFile == "" {
+		return nil, errors.New("auth: either HS256 secret or JWKS file must be configured")
+	}
+
+	v := &Verifier{cfg: cfg, tenants: make(map[string][]string, len(cfg.TenantRoutes))}
+	for tenant, routes := range cfg.TenantRoutes {
+		v.tenants[strings.ToLower(tenant)] = routes
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: load jwks: %w", err)
+		}
+		v.keys = keys
+	}
+
+	return v, nil
+}
+
+// driverClaims is the claims of the driver token, sub is the driver id.
+type driverClaims struct {
+	BusIDs []string `json:"bus_ids"`
+	jwt.RegisteredClaims
+}
+
+// Driver denotes an authenticated driver and the buses it is allowed to report.
+type Driver struct {
+	ID     string
+	BusIDs []string
+}
+
+// VerifyDriver verify the driver token.
+func (v *Verifier) VerifyDriver(token string) (Driver, error) {
+	var claims driverClaims
+	if err := v.parse(token, &claims); err != nil {
+		return Driver{}, err
+	}
+	if claims.Subject == "" {
+		return Driver{}, ErrInvalidToken
+	}
+
+	return Driver{ID: claims.Subject, BusIDs: claims.BusIDs}, nil
+}
+
+// Authorize check the driver is allowed to report the location of its bus
+// and record the driver on the location.
+func (d Driver) Authorize(l *track.Location) error {
+	for _, id := range d.BusIDs {
+		if id == l.Bus.ID {
+			l.Driver = track.Driver{ID: d.ID}
+			return nil
+		}
+	}
+	return ErrBusNotAllowed
+}
+
+// AuthorizeTrip check the driver is allowed to drive the bus of the trip
+// and record the driver on the trip.
+func (d Driver) AuthorizeTrip(t *track.Trip) error {
+	for _, id := range d.BusIDs {
+		if id == t.Bus.ID {
+			t.Driver = track.Driver{ID: d.ID}
+			return nil
+		}
+	}
+	return ErrBusNotAllowed
+}
+
+// riderClaims is the claims of the rider token, sub is the customer id.
+// The rider can only follow the buses in bus_ids, or serving one of the routes in route_ids
+// or of the routes covered by its tenant.
+type riderClaims struct {
+	Tenant   string   `json:"tenant"`
+	BusIDs   []string `json:"bus_ids"`
+	RouteIDs []string `json:"route_ids"`
+	jwt.RegisteredClaims
+}
+
+// VerifyRider verify the rider token and returns the customer it is issued to.
+func (v *Verifier) VerifyRider(token string) (track.Customer, error) {
+	var claims riderClaims
+	if err := v.parse(token, &claims); err != nil {
+		return track.Customer{}, err
+	}
+	if claims.Subject == "" {
+		return track.Customer{}, ErrInvalidToken
+	}
+
+	routeIDs := claims.RouteIDs
+	if routes := v.tenants[strings.ToLower(claims.Tenant)]; claims.Tenant != "" && len(routes) > 0 {
+		routeIDs = append(append([]string(nil), claims.RouteIDs...), routes...)
+	}
+
+	return track.Customer{
+		ID:     claims.Subject,
+		Tenant: claims.Tenant,
+		Permission: &track.Permission{
+			BusIDs:   claims.BusIDs,
+			RouteIDs: routeIDs,
+		},
+	}, nil
+}
+
+func (v *Verifier) parse(token string, claims jwt.Claims) error {
+	if token == "" {
+		return ErrMissingToken
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(v.methods()),
+		jwt.WithExpirationRequired(),
+	}
+	if v.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.cfg.Issuer))
+	}
+	if v.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.cfg.Audience))
+	}
+
+	if _, err := jwt.ParseWithClaims(token, claims, v.key, opts...); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return nil
+}
+
+func (v *Verifier) methods() []string {
+	var methods []string
+	if len(v.cfg.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if v.cfg.JWKSFile != "" {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	return methods
+}
+
+// key returns the key verifying the token, the methods are already restricted by the parser.
+func (v *Verifier) key(t *jwt.Token) (interface{}, error) {
+	if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		return v.cfg.HS256Secret, nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	return v.keys.key(kid)
+}
+
+// BearerToken returns the token of the Authorization header.
+func BearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// RequestToken returns the bearer token, or the value of the cookie when there is no Authorization header.
+// Browser can't set header on websocket and EventSource request, so the rider token is also accepted as cookie.
+func RequestToken(r *http.Request, cookie string) string {
+	if token := BearerToken(r); token != "" {
+		return token
+	}
+	if cookie == "" {
+		return ""
+	}
+	if c, err := r.Cookie(cookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func driverToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, exp time.Time) string {
+	token := jwt.NewWithClaims(method, driverClaims{
+		BusIDs: []string{"1"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "driver-1",
+			Issuer:    "tracking",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func TestVerifyDriverHS256(t *testing.T) {
+	secret := []byte("secret")
+	v, err := NewVerifier(Config{HS256Secret: secret, Issuer: "tracking"})
+	require.NoError(t, err)
+
+	d, err := v.VerifyDriver(driverToken(t, jwt.SigningMethodHS256, secret, "", time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	assert.Equal(t, Driver{ID: "driver-1", BusIDs: []string{"1"}}, d)
+
+	_, err = v.VerifyDriver("")
+	assert.ErrorIs(t, err, ErrMissingToken)
+
+	_, err = v.VerifyDriver(driverToken(t, jwt.SigningMethodHS256, secret, "", time.Now().Add(-time.Hour)))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = v.VerifyDriver(driverToken(t, jwt.SigningMethodHS256, []byte("other"), "", time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// RS256 is not accepted without a JWKS file.
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = v.VerifyDriver(driverToken(t, jwt.SigningMethodRS256, key, "", time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifyDriverRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+
+	v, err := NewVerifier(Config{JWKSFile: path})
+	require.NoError(t, err)
+
+	d, err := v.VerifyDriver(driverToken(t, jwt.SigningMethodRS256, key, "key-1", time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	assert.Equal(t, "driver-1", d.ID)
+
+	_, err = v.VerifyDriver(driverToken(t, jwt.SigningMethodRS256, key, "key-2", time.Now().Add(time.Hour)))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestDriverAuthorize(t *testing.T) {
+	d := Driver{ID: "driver-1", BusIDs: []string{"1"}}
+
+	l := track.Location{Bus: track.Bus{ID: "1"}}
+	require.NoError(t, d.Authorize(&l))
+	assert.Equal(t, "driver-1", l.Driver.ID)
+
+	l = track.Location{Bus: track.Bus{ID: "2"}}
+	assert.ErrorIs(t, d.Authorize(&l), ErrBusNotAllowed)
+	assert.Empty(t, l.Driver.ID)
+}
+
+func TestVerifyRider(t *testing.T) {
+	secret := []byte("secret")
+	v, err := NewVerifier(Config{HS256Secret: secret})
+	require.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, riderClaims{
+		Tenant:   "tenant-1",
+		RouteIDs: []string{"r1"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "rider-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(secret)
+	require.NoError(t, err)
+
+	c, err := v.VerifyRider(token)
+	require.NoError(t, err)
+	assert.Equal(t, track.Customer{
+		ID:         "rider-1",
+		Tenant:     "tenant-1",
+		Permission: &track.Permission{RouteIDs: []string{"r1"}},
+	}, c)
+
+	// the rider can also follow the routes covered by its tenant.
+	v, err = NewVerifier(Config{HS256Secret: secret, TenantRoutes: map[string][]string{"Tenant-1": {"r2", "r3"}}})
+	require.NoError(t, err)
+	c, err = v.VerifyRider(token)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2", "r3"}, c.Permission.RouteIDs)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"sort"
 	"sync"
 	"time"
@@ -157,7 +158,18 @@ func SendLocation(n int, m *Metric) {
 		}
 		body := bytes.NewReader(b)
 		url := fmt.Sprintf("http://%s/%s?bus_id=1", BaseURLDriver, "location")
-		resp, err := http.Post(url, "application/json", body)
+		req, err := http.NewRequest(http.MethodPost, url, body)
+		if err != nil {
+			log.Error().Err(err).Msg("error when creating http request")
+			m.Failure(Driver)
+			continue
+		}
+		req.Header.Set("Content-Type", "application/json")
+		// driver-service require a driver token of bus 1 when authentication is enabled.
+		if token := os.Getenv("DRIVER_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			log.Error().Err(err).Msg("error when calling http request")
 			m.Failure(Driver)
@@ -186,14 +198,18 @@ func ReceiveLocation(n, i int, m *Metric, ready chan struct{}) {
 	var wg sync.WaitGroup
 	wg.Add(n)
 	for usr := 0; usr < n; usr++ {
-		go func() {
+		go func(usr int) {
 			log.Info().Any("user", usr).Msg("starting connection")
 			defer wg.Done()
 			m.Total(Tracker)
 
-			url := fmt.Sprintf("ws://%s/%s", BaseURLTracker, "location")
+			url := fmt.Sprintf("ws://%s/%s?bus_id=1", BaseURLTracker, "location")
 			header := http.Header{}
 			header.Add("Content-Type", "application/json")
+			// tracking-service require a rider token covering bus 1 when authentication is enabled.
+			if token := os.Getenv("RIDER_TOKEN"); token != "" {
+				header.Add("Authorization", "Bearer "+token)
+			}
 			dial, _, err := websocket.DefaultDialer.Dial(url, header)
 			if err != nil {
 				log.Error().Err(err).Msg("error when dialing websocket")
@@ -223,7 +239,7 @@ func ReceiveLocation(n, i int, m *Metric, ready chan struct{}) {
 				m.ResponseTime(time.Since(ts).Seconds())
 			}
 
-		}()
+		}(usr)
 	}
 	wg.Wait()
 }
@@ -0,0 +1,32 @@
+// Package broker defines the contract between the services and the message broker
+// carrying the location from driver-service to tracking-service.
+package broker
+
+import (
+	"context"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+// Receiver will receive every location and trip event consumed from the broker.
+type Receiver interface {
+	Receive(l track.Location)
+	ReceiveTrip(t track.Trip)
+}
+
+// Publisher publish location and trip event to the broker,
+// it satisfies track.Sender, track.BatchSender and track.TripSender.
+// Trip event of a bus is published in order with its location.
+type Publisher interface {
+	Send(ctx context.Context, l track.Location) error
+	SendBatch(ctx context.Context, locs []track.Location) error
+	SendTrip(ctx context.Context, t track.Trip) error
+	Close() error
+}
+
+// Subscriber consume location from the broker and pass it to its Receiver.
+// Listen blocks until the subscriber is closed.
+type Subscriber interface {
+	Listen(ctx context.Context)
+	Close() error
+}
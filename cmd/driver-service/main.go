@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"flag"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,14 +12,24 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/nats-io/nats.go"
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/codec"
 	"github.com/rafimuhammad01/tracking-app/config"
+	igrpc "github.com/rafimuhammad01/tracking-app/grpc"
 	ihttp "github.com/rafimuhammad01/tracking-app/http"
 	ikafka "github.com/rafimuhammad01/tracking-app/kafka"
+	inats "github.com/rafimuhammad01/tracking-app/nats"
+	"github.com/rafimuhammad01/tracking-app/outbox"
+	iredis "github.com/rafimuhammad01/tracking-app/redis"
 	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/scram"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -41,6 +52,15 @@ func main() {
 
 	// server definition
 	srv := NewHTTPServer(dep)
+	grpcSrv := NewGRPCServer(dep)
+
+	// start outbox
+	if dep.Outbox != nil {
+		go func() {
+			log.Info().Int64("pending", dep.Outbox.Pending()).Msg("starting outbox")
+			dep.Outbox.Run(ctx)
+		}()
+	}
 
 	// start server
 	go func() {
@@ -50,6 +70,19 @@ func main() {
 		}
 	}()
 
+	if port := config.Get().GRPC.DriverPort; port != "" {
+		go func() {
+			log.Info().Str("port", port).Msg("starting grpc server")
+			lis, err := net.Listen("tcp", ":"+port)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to listen grpc server")
+			}
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatal().Err(err).Msg("failed to start grpc server")
+			}
+		}()
+	}
+
 	// graceful shutdown
 	<-done
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -57,6 +90,24 @@ func main() {
 
 	var wg sync.WaitGroup
 	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		log.Info().Msg("stopping grpc server")
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+
+		// stream that doesn't end by itself is cancelled once the timeout is reached.
+		select {
+		case <-ctx.Done():
+			grpcSrv.Stop()
+		case <-stopped:
+		}
+		log.Info().Msg("grpc server stopped")
+	}()
+
 	go func() {
 		defer wg.Done()
 		log.Info().Msg("stopping http server")
@@ -67,49 +118,75 @@ func main() {
 		}
 	}()
 
-	go func() {
-		defer wg.Done()
-		log.Info().Msg("stopping kafka producer connection")
-		kafkaClosed := make(chan struct{})
-		kafkaClosedErr := make(chan error)
-		go func() {
-			if err := dep.KafkaTracker.WriterCloser(); err != nil {
-				kafkaClosedErr <- err
-			} else {
-				kafkaClosed <- struct{}{}
-			}
+	// in flight request still send location to the outbox,
+	// so it is closed once both servers are stopped, and the producer it uses after it.
+	wg.Wait()
 
-		}()
+	if dep.Outbox != nil {
+		log.Info().Msg("stopping outbox")
+		if err := dep.Outbox.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close outbox")
+		} else {
+			log.Info().Msg("outbox stopped")
+		}
+	}
 
-		select {
-		case <-ctx.Done():
-			log.Fatal().Err(ctx.Err()).Msg("failed to close kafka producer connection")
-		case err := <-kafkaClosedErr:
-			log.Fatal().Err(err).Msg("failed to close kafka producer connection")
-		case <-kafkaClosed:
-			log.Info().Msg("kafka producer connection closed")
+	log.Info().Msg("stopping broker producer connection")
+	brokerClosed := make(chan struct{})
+	brokerClosedErr := make(chan error)
+	go func() {
+		if err := dep.Publisher.Close(); err != nil {
+			brokerClosedErr <- err
+		} else {
+			brokerClosed <- struct{}{}
 		}
 	}()
-	wg.Wait()
+
+	select {
+	case <-ctx.Done():
+		log.Fatal().Err(ctx.Err()).Msg("failed to close broker producer connection")
+	case err := <-brokerClosedErr:
+		log.Fatal().Err(err).Msg("failed to close broker producer connection")
+	case <-brokerClosed:
+		log.Info().Msg("broker producer connection closed")
+	}
 }
 
 type Dependency struct {
-	Tracker      *track.Tracker
-	HTTPHandler  *ihttp.TrackingHandler
-	KafkaTracker *ikafka.Tracker
+	Tracker     *track.Tracker
+	HTTPHandler *ihttp.TrackingHandler
+	GRPCServer  *igrpc.TrackingServer
+	Publisher   broker.Publisher
+	Outbox      *outbox.Outbox
 }
 
 func InitDependency() *Dependency {
-	writer := NewKafkaWriter()
-	kafkaTracker := ikafka.NewTracker(ikafka.WithWriter(writer))
+	publisher := NewPublisher()
 
-	tracker := track.NewTracker(track.WithSender(kafkaTracker))
-	httpHandler := ihttp.NewHandler(tracker)
+	var sender track.Sender = publisher
+	var ob *outbox.Outbox
+	if config.Get().Outbox.Enabled {
+		ob = NewOutbox(publisher)
+		sender = ob
+	}
+
+	// trip event goes through the outbox too, so it is never published before the location buffered before it.
+	tracker := track.NewTracker(track.WithSender(sender), track.WithValidation(track.ValidationConfig{
+		MaxFutureSkew: config.Get().Validation.MaxFutureSkew,
+		MaxPastSkew:   config.Get().Validation.MaxPastSkew,
+	}))
+	driverAuth := NewVerifier("driver", config.Get().Auth.Driver, nil)
+	httpHandler := ihttp.NewHandler(tracker,
+		ihttp.WithMaxBatchSize(config.Get().HTTP.MaxBatchSize),
+		ihttp.WithDriverAuth(driverAuth),
+	)
 
 	return &Dependency{
-		Tracker:      tracker,
-		HTTPHandler:  httpHandler,
-		KafkaTracker: kafkaTracker,
+		Tracker:     tracker,
+		HTTPHandler: httpHandler,
+		GRPCServer:  igrpc.NewServer(tracker, igrpc.WithDriverAuth(driverAuth)),
+		Publisher:   publisher,
+		Outbox:      ob,
 	}
 }
 
@@ -119,10 +196,76 @@ func NewHTTPServer(d *Dependency) *http.Server {
 		Addr: port,
 	}
 
-	http.HandleFunc("/location", d.HTTPHandler.SendLocation)
+	ihttp.RegisterDriverRoutes(http.DefaultServeMux, d.HTTPHandler)
+	return srv
+}
+
+// NewVerifier create the verifier of the token configured by cfg, it returns nil when the authentication is disabled.
+// tenantRoutes is only used by the rider verifier.
+func NewVerifier(name string, cfg config.AuthToken, tenantRoutes map[string][]string) *auth.Verifier {
+	if !cfg.Enabled {
+		log.Warn().Str("auth", name).Msg("authentication is disabled")
+		return nil
+	}
+
+	v, err := auth.NewVerifier(auth.Config{
+		HS256Secret:  []byte(cfg.HS256Secret),
+		JWKSFile:     cfg.JWKSFile,
+		Issuer:       cfg.Issuer,
+		Audience:     cfg.Audience,
+		TenantRoutes: tenantRoutes,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("auth", name).Msg("invalid auth config")
+	}
+	return v
+}
+
+func NewGRPCServer(d *Dependency) *grpc.Server {
+	srv := grpc.NewServer()
+	igrpc.RegisterDriverService(srv, d.GRPCServer)
 	return srv
 }
 
+// NewPublisher create the publisher of the configured broker.
+func NewPublisher() broker.Publisher {
+	c, err := codec.New(config.Get().Broker.Codec)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid broker codec")
+	}
+
+	switch t := config.Get().Broker.Type; t {
+	case "", "kafka":
+		return ikafka.NewTracker(ikafka.WithWriter(NewKafkaWriter()), ikafka.WithCodec(c))
+	case "nats":
+		return inats.NewTracker(NewNATSConn(), config.Get().NATS.Subject, inats.WithCodec(c))
+	case "redis":
+		return iredis.NewTracker(NewRedisClient(), config.Get().Redis.Stream, iredis.WithCodec(c), iredis.WithMaxLen(config.Get().Redis.MaxLen))
+	default:
+		log.Fatal().Str("type", t).Msg("unsupported broker type")
+		return nil
+	}
+}
+
+func NewOutbox(pub track.Sender) *outbox.Outbox {
+	fsync, err := outbox.ParseFsyncPolicy(config.Get().Outbox.Fsync)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid outbox config")
+	}
+
+	ob, err := outbox.Open(config.Get().Outbox.Path, pub, outbox.Config{
+		MaxBytes:      config.Get().Outbox.MaxBytes,
+		Fsync:         fsync,
+		FsyncInterval: config.Get().Outbox.FsyncInterval,
+		RetryInterval: config.Get().Outbox.RetryInterval,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to open outbox")
+	}
+
+	return ob
+}
+
 func NewKafkaWriter() *kafka.Writer {
 	mechanism, err := scram.Mechanism(scram.SHA256, config.Get().Kafka.Connection.Username, config.Get().Kafka.Connection.Password)
 	if err != nil {
@@ -134,12 +277,32 @@ func NewKafkaWriter() *kafka.Writer {
 		TLS:           &tls.Config{},
 	}
 
+	// every write waits for the location of the other drivers until the batch timeout,
+	// kafka-go default of one second would bound the write rate of the outbox.
 	w := kafka.NewWriter(kafka.WriterConfig{
-		Brokers:  config.Get().Kafka.Connection.Brokers,
-		Topic:    config.Get().Kafka.Consumer.Topic,
-		Balancer: &kafka.Hash{},
-		Dialer:   dialer,
+		Brokers:      config.Get().Kafka.Connection.Brokers,
+		Topic:        config.Get().Kafka.Consumer.Topic,
+		Balancer:     &kafka.Hash{},
+		Dialer:       dialer,
+		BatchTimeout: 10 * time.Millisecond,
 	})
 
 	return w
 }
+
+func NewNATSConn() *nats.Conn {
+	conn, err := nats.Connect(config.Get().NATS.URL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to nats")
+	}
+
+	return conn
+}
+
+func NewRedisClient() *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     config.Get().Redis.Addr,
+		Password: config.Get().Redis.Password,
+		DB:       config.Get().Redis.DB,
+	})
+}
@@ -0,0 +1,241 @@
+// Command standalone runs driver-service and tracking-service in a single process
+// connected by the in-memory broker, so local development doesn't need any external broker.
+package main
+
+import (
+	"context"
+	"expvar"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/config"
+	"github.com/rafimuhammad01/tracking-app/gtfs"
+	ihttp "github.com/rafimuhammad01/tracking-app/http"
+	"github.com/rafimuhammad01/tracking-app/memory"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	// config
+	configPath := flag.String("env_file", "./config/development.yaml", "define the environment file path")
+	flag.Parse()
+	config.SetFromFile(*configPath)
+
+	// log setup
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if config.Get().Debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+
+	// app related
+	done := make(chan os.Signal, 1)
+	ctx := context.Background()
+	dep := InitDependency()
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	// server definition
+	servers := NewHTTPServers(dep)
+
+	// start server
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			log.Info().Any("port", srv.Addr).Msg("starting http server")
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().Err(err).Msg("failed to start http server")
+			}
+		}(srv)
+	}
+
+	go func() {
+		log.Info().Msg("starting in-memory broker consumer")
+		dep.Subscriber.Listen(ctx)
+	}()
+
+	if dep.Catalog != nil {
+		go dep.Catalog.Watch(ctx)
+	}
+
+	// graceful shutdown
+	<-done
+
+	// streaming clients are hijacked or never ending request, http server shutdown doesn't wait for them.
+	// they keep receiving location until they leave, so the broker is stopped after.
+	log.Info().Msg("draining streaming clients")
+	drainCtx, drainCancel := context.WithTimeout(ctx, config.Get().HTTP.DrainTimeout)
+	if err := dep.HTTPHandler.Drain(drainCtx); err != nil {
+		log.Warn().Err(err).Msg("streaming clients still connected after drain timeout")
+	} else {
+		log.Info().Msg("streaming clients drained")
+	}
+	drainCancel()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(len(servers))
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			defer wg.Done()
+			log.Info().Any("port", srv.Addr).Msg("stopping http server")
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Fatal().Err(err).Msg("failed to shutdown http server")
+			} else {
+				log.Info().Any("port", srv.Addr).Msg("http server stopped")
+			}
+		}(srv)
+	}
+	wg.Wait()
+
+	dep.Subscriber.Close()
+	dep.Publisher.Close()
+	log.Info().Msg("in-memory broker stopped")
+}
+
+type Dependency struct {
+	Tracker     *track.Tracker
+	HTTPHandler *ihttp.TrackingHandler
+	Publisher   *memory.Tracker
+	Subscriber  *memory.Tracker
+	Catalog     *gtfs.Catalog
+}
+
+func InitDependency() *Dependency {
+	overflow, err := track.ParseOverflowPolicy(config.Get().Hub.OverflowPolicy)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid hub config")
+	}
+
+	// the same tracker send location to the broker and receive it back for the riders.
+	b := memory.NewBroker()
+	publisher := memory.NewTracker(b)
+	// the location is enriched only when the gtfs feed is configured.
+	catalog := NewCatalog()
+	var enricher track.Enricher
+	if catalog != nil {
+		enricher = catalog
+	}
+
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{
+		BufferSize:                config.Get().Hub.BufferSize,
+		Overflow:                  overflow,
+		MaxConnectionsPerCustomer: config.Get().Hub.MaxConnectionsPerCustomer,
+		Shards:                    config.Get().Hub.Shards,
+	}), track.WithCache(config.Get().Cache.TTL), track.WithRouteLookup(NewBusRoutes()), track.WithOrdering(), track.WithSender(publisher), track.WithFilters(NewFilters()...), track.WithValidation(track.ValidationConfig{
+		MaxFutureSkew: config.Get().Validation.MaxFutureSkew,
+		MaxPastSkew:   config.Get().Validation.MaxPastSkew,
+	}), track.WithEnricher(enricher))
+	subscriber := memory.NewTracker(b, memory.WithReceiver(tracker))
+
+	httpHandler := ihttp.NewHandler(tracker,
+		ihttp.WithMaxBatchSize(config.Get().HTTP.MaxBatchSize),
+		ihttp.WithDriverAuth(NewVerifier("driver", config.Get().Auth.Driver, nil)),
+		ihttp.WithRiderAuth(NewVerifier("rider", config.Get().Auth.Rider.AuthToken, config.Get().Auth.Rider.TenantRoutes), config.Get().Auth.Rider.SessionCookie),
+		ihttp.WithWebSocket(NewWebSocketConfig()),
+		ihttp.WithReconnectBackoff(config.Get().HTTP.ReconnectBackoff),
+	)
+
+	return &Dependency{
+		Tracker:     tracker,
+		HTTPHandler: httpHandler,
+		Publisher:   publisher,
+		Subscriber:  subscriber,
+		Catalog:     catalog,
+	}
+}
+
+// NewVerifier create the verifier of the token configured by cfg, it returns nil when the authentication is disabled.
+// tenantRoutes is only used by the rider verifier.
+func NewVerifier(name string, cfg config.AuthToken, tenantRoutes map[string][]string) *auth.Verifier {
+	if !cfg.Enabled {
+		log.Warn().Str("auth", name).Msg("authentication is disabled")
+		return nil
+	}
+
+	v, err := auth.NewVerifier(auth.Config{
+		HS256Secret:  []byte(cfg.HS256Secret),
+		JWKSFile:     cfg.JWKSFile,
+		Issuer:       cfg.Issuer,
+		Audience:     cfg.Audience,
+		TenantRoutes: tenantRoutes,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("auth", name).Msg("invalid auth config")
+	}
+	return v
+}
+
+// NewCatalog load the configured gtfs feed, it returns nil when no feed is configured.
+func NewCatalog() *gtfs.Catalog {
+	path := config.Get().GTFS.Path
+	if path == "" {
+		return nil
+	}
+
+	c, err := gtfs.NewCatalog(path, gtfs.WithReloadInterval(config.Get().GTFS.ReloadInterval))
+	if err != nil {
+		log.Fatal().Err(err).Str("path", path).Msg("failed to load gtfs feed")
+	}
+	return c
+}
+
+// NewFilters create the filters enabled in the config, in the order they are applied.
+// Rejected location is not sent anywhere, the in-memory broker has no diagnostics topic.
+// NewBusRoutes returns the configured route of every bus by bus id.
+func NewBusRoutes() track.BusRoutes {
+	routes := make(track.BusRoutes, len(config.Get().BusRoutes))
+	for _, r := range config.Get().BusRoutes {
+		if r.BusID == "" || r.RouteID == "" {
+			log.Fatal().Any("bus_route", r).Msg("invalid bus_routes config")
+		}
+		routes[r.BusID] = r.RouteID
+	}
+	return routes
+}
+
+func NewFilters() []track.Filter {
+	var filters []track.Filter
+	if v := config.Get().Filter.MaxSpeed; v > 0 {
+		filters = append(filters, track.NewMaxSpeedFilter(v))
+	}
+	if v := config.Get().Filter.KalmanNoise; v > 0 {
+		filters = append(filters, track.NewKalmanFilter(v))
+	}
+	if v := config.Get().Filter.StationaryRadius; v > 0 {
+		filters = append(filters, track.NewStationaryFilter(v))
+	}
+	return filters
+}
+
+func NewWebSocketConfig() ihttp.WebSocketConfig {
+	return ihttp.WebSocketConfig{
+		PingInterval: config.Get().WebSocket.PingInterval,
+		PongTimeout:  config.Get().WebSocket.PongTimeout,
+		WriteTimeout: config.Get().WebSocket.WriteTimeout,
+		MaxIdle:      config.Get().WebSocket.MaxIdle,
+	}
+}
+
+// NewHTTPServers create the driver and tracker server on their configured port.
+func NewHTTPServers(d *Dependency) []*http.Server {
+	driverMux := http.NewServeMux()
+	driverMux.Handle("/debug/vars", expvar.Handler())
+	ihttp.RegisterDriverRoutes(driverMux, d.HTTPHandler)
+
+	trackerMux := http.NewServeMux()
+	trackerMux.Handle("/debug/vars", expvar.Handler())
+	ihttp.RegisterTrackerRoutes(trackerMux, d.HTTPHandler)
+
+	return []*http.Server{
+		{Addr: ":" + config.Get().HTTP.DriverPort, Handler: driverMux},
+		{Addr: ":" + config.Get().HTTP.TrackerPort, Handler: trackerMux},
+	}
+}
@@ -8,19 +8,30 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/scram"
+	"google.golang.org/grpc"
 
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/codec"
 	"github.com/rafimuhammad01/tracking-app/config"
+	igrpc "github.com/rafimuhammad01/tracking-app/grpc"
+	"github.com/rafimuhammad01/tracking-app/gtfs"
 	ihttp "github.com/rafimuhammad01/tracking-app/http"
 	ikafka "github.com/rafimuhammad01/tracking-app/kafka"
+	inats "github.com/rafimuhammad01/tracking-app/nats"
+	iredis "github.com/rafimuhammad01/tracking-app/redis"
 
 	"github.com/rafimuhammad01/tracking-app/track"
 )
@@ -45,6 +56,7 @@ func main() {
 
 	// server definition
 	srv := NewHTTPServer(dep)
+	grpcSrv := NewGRPCServer(dep)
 
 	// start server
 	go func() {
@@ -55,17 +67,64 @@ func main() {
 	}()
 
 	go func() {
-		log.Info().Any("topic", dep.KafkaTracker.ReaderTopic().Topic).Msg("starting kafka consumer")
-		dep.KafkaTracker.Listen(ctx)
+		log.Info().Str("type", config.Get().Broker.Type).Msg("starting broker consumer")
+		dep.Subscriber.Listen(ctx)
 	}()
 
+	if dep.Catalog != nil {
+		go dep.Catalog.Watch(ctx)
+	}
+
+	if port := config.Get().GRPC.TrackerPort; port != "" {
+		go func() {
+			log.Info().Str("port", port).Msg("starting grpc server")
+			lis, err := net.Listen("tcp", ":"+port)
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to listen grpc server")
+			}
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatal().Err(err).Msg("failed to start grpc server")
+			}
+		}()
+	}
+
 	// graceful shutdown
 	<-done
+
+	// streaming clients are hijacked or never ending request, http server shutdown doesn't wait for them.
+	// they keep receiving location until they leave, so the broker is stopped after.
+	log.Info().Msg("draining streaming clients")
+	drainCtx, drainCancel := context.WithTimeout(ctx, config.Get().HTTP.DrainTimeout)
+	if err := dep.HTTPHandler.Drain(drainCtx); err != nil {
+		log.Warn().Err(err).Msg("streaming clients still connected after drain timeout")
+	} else {
+		log.Info().Msg("streaming clients drained")
+	}
+	drainCancel()
+
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		log.Info().Msg("stopping grpc server")
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+
+		// stream that doesn't end by itself is cancelled once the timeout is reached.
+		select {
+		case <-ctx.Done():
+			grpcSrv.Stop()
+		case <-stopped:
+		}
+		log.Info().Msg("grpc server stopped")
+	}()
+
 	go func() {
 		defer wg.Done()
 		log.Info().Msg("stopping http server")
@@ -78,47 +137,88 @@ func main() {
 
 	go func() {
 		defer wg.Done()
-		log.Info().Msg("stopping kafka consumer")
-		kafkaClosed := make(chan struct{})
-		kafkaClosedErr := make(chan error)
+		log.Info().Msg("stopping broker consumer")
+		brokerClosed := make(chan struct{})
+		brokerClosedErr := make(chan error)
 		go func() {
-			if err := dep.KafkaTracker.ReaderCloser(); err != nil {
-				kafkaClosedErr <- err
+			if err := dep.Subscriber.Close(); err != nil {
+				brokerClosedErr <- err
 			} else {
-				kafkaClosed <- struct{}{}
+				brokerClosed <- struct{}{}
 			}
 
 		}()
 
 		select {
 		case <-ctx.Done():
-			log.Fatal().Err(ctx.Err()).Msg("failed to shutdown kafka consumer")
-		case err := <-kafkaClosedErr:
-			log.Fatal().Err(err).Msg("failed to shutdown kafka consumer")
-		case <-kafkaClosed:
-			log.Info().Msg("kafka consumer stopped")
+			log.Fatal().Err(ctx.Err()).Msg("failed to shutdown broker consumer")
+		case err := <-brokerClosedErr:
+			log.Fatal().Err(err).Msg("failed to shutdown broker consumer")
+		case <-brokerClosed:
+			log.Info().Msg("broker consumer stopped")
+		}
+
+		// the diagnostics publisher is used by the consumer, so it is closed after.
+		if dep.Diagnostics != nil {
+			if err := dep.Diagnostics.Close(); err != nil {
+				log.Error().Err(err).Msg("failed to close diagnostics publisher")
+			} else {
+				log.Info().Msg("diagnostics publisher stopped")
+			}
 		}
 	}()
 	wg.Wait()
 }
 
 type Dependency struct {
-	Tracker      *track.Tracker
-	HTTPHandler  *ihttp.TrackingHandler
-	KafkaTracker *ikafka.Tracker
+	Tracker     *track.Tracker
+	HTTPHandler *ihttp.TrackingHandler
+	GRPCServer  *igrpc.TrackingServer
+	Subscriber  broker.Subscriber
+	Diagnostics broker.Publisher
+	Catalog     *gtfs.Catalog
 }
 
 func InitDependency() *Dependency {
-	tracker := track.NewTracker(track.WithHub())
+	overflow, err := track.ParseOverflowPolicy(config.Get().Hub.OverflowPolicy)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid hub config")
+	}
+
+	var diagnostics broker.Publisher
+	if name := config.Get().Filter.Diagnostics; name != "" {
+		diagnostics = NewDiagnosticsPublisher(name)
+	}
+
+	// the location is enriched only when the gtfs feed is configured.
+	catalog := NewCatalog()
+	var enricher track.Enricher
+	if catalog != nil {
+		enricher = catalog
+	}
+
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{
+		BufferSize:                config.Get().Hub.BufferSize,
+		Overflow:                  overflow,
+		MaxConnectionsPerCustomer: config.Get().Hub.MaxConnectionsPerCustomer,
+		Shards:                    config.Get().Hub.Shards,
+	}), track.WithCache(config.Get().Cache.TTL), track.WithRouteLookup(NewBusRoutes()), track.WithOrdering(), track.WithFilters(NewFilters()...), track.WithDiagnostics(diagnostics), track.WithEnricher(enricher))
 
-	consumer := NewKafkaConsumer()
-	httpHandler := ihttp.NewHandler(tracker)
-	kafkaTracker := ikafka.NewTracker(ikafka.WithReceiver(tracker, consumer))
+	riderAuth := NewVerifier("rider", config.Get().Auth.Rider.AuthToken, config.Get().Auth.Rider.TenantRoutes)
+	httpHandler := ihttp.NewHandler(tracker,
+		ihttp.WithWebSocket(NewWebSocketConfig()),
+		ihttp.WithRiderAuth(riderAuth, config.Get().Auth.Rider.SessionCookie),
+		ihttp.WithReconnectBackoff(config.Get().HTTP.ReconnectBackoff),
+	)
+	subscriber := NewSubscriber(tracker)
 
 	return &Dependency{
-		Tracker:      tracker,
-		HTTPHandler:  httpHandler,
-		KafkaTracker: kafkaTracker,
+		Tracker:     tracker,
+		HTTPHandler: httpHandler,
+		GRPCServer:  igrpc.NewServer(tracker, igrpc.WithRiderAuth(riderAuth)),
+		Subscriber:  subscriber,
+		Diagnostics: diagnostics,
+		Catalog:     catalog,
 	}
 }
 
@@ -128,21 +228,149 @@ func NewHTTPServer(d *Dependency) *http.Server {
 		Addr: port,
 	}
 
-	http.HandleFunc("/location", d.HTTPHandler.GetLatestLocation)
+	ihttp.RegisterTrackerRoutes(http.DefaultServeMux, d.HTTPHandler)
 	return srv
 }
 
-func NewKafkaConsumer() *kafka.Reader {
+// NewVerifier create the verifier of the token configured by cfg, it returns nil when the authentication is disabled.
+// tenantRoutes is only used by the rider verifier.
+func NewVerifier(name string, cfg config.AuthToken, tenantRoutes map[string][]string) *auth.Verifier {
+	if !cfg.Enabled {
+		log.Warn().Str("auth", name).Msg("authentication is disabled")
+		return nil
+	}
+
+	v, err := auth.NewVerifier(auth.Config{
+		HS256Secret:  []byte(cfg.HS256Secret),
+		JWKSFile:     cfg.JWKSFile,
+		Issuer:       cfg.Issuer,
+		Audience:     cfg.Audience,
+		TenantRoutes: tenantRoutes,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Str("auth", name).Msg("invalid auth config")
+	}
+	return v
+}
+
+func NewWebSocketConfig() ihttp.WebSocketConfig {
+	return ihttp.WebSocketConfig{
+		PingInterval: config.Get().WebSocket.PingInterval,
+		PongTimeout:  config.Get().WebSocket.PongTimeout,
+		WriteTimeout: config.Get().WebSocket.WriteTimeout,
+		MaxIdle:      config.Get().WebSocket.MaxIdle,
+	}
+}
+
+func NewGRPCServer(d *Dependency) *grpc.Server {
+	srv := grpc.NewServer()
+	igrpc.RegisterRiderService(srv, d.GRPCServer)
+	return srv
+}
+
+// NewSubscriber create the subscriber of the configured broker.
+func NewSubscriber(rc broker.Receiver) broker.Subscriber {
+	switch t := config.Get().Broker.Type; t {
+	case "", "kafka":
+		var deadLetter *kafka.Writer
+		if topic := config.Get().Kafka.DeadLetter.Topic; topic != "" {
+			deadLetter = NewKafkaDeadLetterWriter(topic)
+		}
+		return ikafka.NewTracker(ikafka.WithReceiver(rc, NewKafkaConsumer()), ikafka.WithDeadLetter(deadLetter))
+	case "nats":
+		return inats.NewTracker(NewNATSConn(), config.Get().NATS.Subject, inats.WithReceiver(rc))
+	case "redis":
+		return iredis.NewTracker(NewRedisClient(), config.Get().Redis.Stream, iredis.WithReceiver(rc))
+	default:
+		log.Fatal().Str("type", t).Msg("unsupported broker type")
+		return nil
+	}
+}
+
+// NewCatalog load the configured gtfs feed, it returns nil when no feed is configured.
+func NewCatalog() *gtfs.Catalog {
+	path := config.Get().GTFS.Path
+	if path == "" {
+		return nil
+	}
+
+	c, err := gtfs.NewCatalog(path, gtfs.WithReloadInterval(config.Get().GTFS.ReloadInterval))
+	if err != nil {
+		log.Fatal().Err(err).Str("path", path).Msg("failed to load gtfs feed")
+	}
+	return c
+}
+
+// NewFilters create the filters enabled in the config, in the order they are applied.
+// NewBusRoutes returns the configured route of every bus by bus id.
+func NewBusRoutes() track.BusRoutes {
+	routes := make(track.BusRoutes, len(config.Get().BusRoutes))
+	for _, r := range config.Get().BusRoutes {
+		if r.BusID == "" || r.RouteID == "" {
+			log.Fatal().Any("bus_route", r).Msg("invalid bus_routes config")
+		}
+		routes[r.BusID] = r.RouteID
+	}
+	return routes
+}
+
+func NewFilters() []track.Filter {
+	var filters []track.Filter
+	if v := config.Get().Filter.MaxSpeed; v > 0 {
+		filters = append(filters, track.NewMaxSpeedFilter(v))
+	}
+	if v := config.Get().Filter.KalmanNoise; v > 0 {
+		filters = append(filters, track.NewKalmanFilter(v))
+	}
+	if v := config.Get().Filter.StationaryRadius; v > 0 {
+		filters = append(filters, track.NewStationaryFilter(v))
+	}
+	return filters
+}
+
+// NewDiagnosticsPublisher create the publisher of the rejected location,
+// name is the topic, subject or stream of the configured broker.
+func NewDiagnosticsPublisher(name string) broker.Publisher {
+	c, err := codec.New(config.Get().Broker.Codec)
+	if err != nil {
+		log.Fatal().Err(err).Msg("invalid broker codec")
+	}
+
+	switch t := config.Get().Broker.Type; t {
+	case "", "kafka":
+		// rejected location is only for diagnostics, it must not slow down the consumer.
+		w := kafka.NewWriter(kafka.WriterConfig{
+			Brokers:  config.Get().Kafka.Connection.Brokers,
+			Topic:    name,
+			Balancer: &kafka.Hash{},
+			Dialer:   NewKafkaDialer(),
+			Async:    true,
+		})
+		return ikafka.NewTracker(ikafka.WithWriter(w), ikafka.WithCodec(c))
+	case "nats":
+		return inats.NewTracker(NewNATSConn(), name, inats.WithCodec(c))
+	case "redis":
+		return iredis.NewTracker(NewRedisClient(), name, iredis.WithCodec(c), iredis.WithMaxLen(config.Get().Redis.MaxLen))
+	default:
+		log.Fatal().Str("type", t).Msg("unsupported broker type")
+		return nil
+	}
+}
+
+func NewKafkaDialer() *kafka.Dialer {
 	mechanism, err := scram.Mechanism(scram.SHA256, config.Get().Kafka.Connection.Username, config.Get().Kafka.Connection.Password)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start kafka consumer")
 	}
 
-	dialer := &kafka.Dialer{
+	return &kafka.Dialer{
 		SASLMechanism: mechanism,
 		TLS:           &tls.Config{},
 	}
+}
 
+func NewKafkaConsumer() *kafka.Reader {
+	dialer := NewKafkaDialer()
 	r := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     config.Get().Kafka.Connection.Brokers,
 		Topic:       config.Get().Kafka.Consumer.Topic,
@@ -155,3 +383,29 @@ func NewKafkaConsumer() *kafka.Reader {
 
 	return r
 }
+
+func NewKafkaDeadLetterWriter(topic string) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  config.Get().Kafka.Connection.Brokers,
+		Topic:    topic,
+		Balancer: &kafka.Hash{},
+		Dialer:   NewKafkaDialer(),
+	})
+}
+
+func NewNATSConn() *nats.Conn {
+	conn, err := nats.Connect(config.Get().NATS.URL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to nats")
+	}
+
+	return conn
+}
+
+func NewRedisClient() *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     config.Get().Redis.Addr,
+		Password: config.Get().Redis.Password,
+		DB:       config.Get().Redis.DB,
+	})
+}
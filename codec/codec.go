@@ -0,0 +1,70 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+// HeaderContentType is the message header carrying the codec content type,
+// so producer and consumer can be upgraded independently.
+const HeaderContentType = "content-type"
+
+// Content type of each codec, the schema version is part of it.
+// Message without content type is decoded with the legacy codec.
+const (
+	ContentTypeJSON     = "application/vnd.tracking.location.v1+json"
+	ContentTypeProtobuf = "application/vnd.tracking.location.v1+protobuf"
+	ContentTypeMsgpack  = "application/vnd.tracking.location.v1+msgpack"
+)
+
+// Codec encode and decode location for the message transport.
+type Codec interface {
+	// ContentType identifies the encoding and the schema version.
+	ContentType() string
+	Encode(l track.Location) ([]byte, error)
+	Decode(b []byte) (track.Location, error)
+}
+
+var codecs = map[string]Codec{
+	ContentTypeJSON:     JSON{},
+	ContentTypeProtobuf: Protobuf{},
+	ContentTypeMsgpack:  Msgpack{},
+}
+
+// New returns the codec by its config name: json, protobuf or msgpack.
+// Empty name will be defaulted to json.
+func New(name string) (Codec, error) {
+	switch name {
+	case "", "json":
+		return JSON{}, nil
+	case "protobuf":
+		return Protobuf{}, nil
+	case "msgpack":
+		return Msgpack{}, nil
+	default:
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+}
+
+// Decode the message with the codec of its content type.
+func Decode(contentType string, b []byte) (track.Location, error) {
+	c, err := ForContentType(contentType)
+	if err != nil {
+		return track.Location{}, err
+	}
+	return c.Decode(b)
+}
+
+// ForContentType returns the codec able to decode the content type.
+func ForContentType(contentType string) (Codec, error) {
+	if contentType == "" {
+		return Legacy{}, nil
+	}
+
+	c, ok := codecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return c, nil
+}
@@ -0,0 +1,82 @@
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	accuracy, altitude, speed, bearing, satellites := 4.5, 0.0, 12.5, 270.0, 7
+	l := track.Location{
+		Long:       106.8272,
+		Lat:        -6.1754,
+		Bus:        track.Bus{ID: "1"},
+		Driver:     track.Driver{ID: "driver-1"},
+		Timestamp:  time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC),
+		Accuracy:   &accuracy,
+		Altitude:   &altitude,
+		Speed:      &speed,
+		Bearing:    &bearing,
+		Satellites: &satellites,
+		Source:     track.SourceGPS,
+		Seq:        42,
+		TripID:     "trip-1",
+	}
+
+	for _, name := range []string{"json", "protobuf", "msgpack"} {
+		t.Run(name, func(t *testing.T) {
+			c, err := New(name)
+			assert.NoError(t, err)
+
+			b, err := c.Encode(l)
+			assert.NoError(t, err)
+
+			// the consumer pick the codec from the content type header.
+			dc, err := ForContentType(c.ContentType())
+			assert.NoError(t, err)
+
+			got, err := dc.Decode(b)
+			assert.NoError(t, err)
+			assert.True(t, l.Timestamp.Equal(got.Timestamp))
+			got.Timestamp = l.Timestamp
+			assert.Equal(t, l, got)
+		})
+	}
+}
+
+func TestTripRoundTrip(t *testing.T) {
+	trip := track.Trip{
+		ID:        "trip-1",
+		Bus:       track.Bus{ID: "1"},
+		RouteID:   "route-1",
+		Driver:    track.Driver{ID: "driver-1"},
+		Status:    track.TripPaused,
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := EncodeTrip(trip)
+	assert.NoError(t, err)
+	got, err := DecodeTrip(b)
+	assert.NoError(t, err)
+	assert.Equal(t, trip, got)
+}
+
+func TestLegacyDecode(t *testing.T) {
+	c, err := ForContentType("")
+	assert.NoError(t, err)
+
+	got, err := c.Decode([]byte(`{"Long":1,"Lat":2,"Bus":{"ID":"3"},"Timestamp":"2024-01-02T03:04:05Z"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, track.Location{
+		Long:      1,
+		Lat:       2,
+		Bus:       track.Bus{ID: "3"},
+		Timestamp: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, got)
+
+	_, err = ForContentType("text/plain")
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+// locationV1 is the version 1 wire schema of the location,
+// shared by the JSON and MessagePack codec.
+type locationV1 struct {
+	BusID     string    `json:"bus_id" msgpack:"bus_id"`
+	Lat       float64   `json:"lat" msgpack:"lat"`
+	Long      float64   `json:"long" msgpack:"long"`
+	Timestamp time.Time `json:"timestamp" msgpack:"timestamp"`
+	// DriverID is added later, it is empty for location sent before driver authentication.
+	DriverID   string   `json:"driver_id,omitempty" msgpack:"driver_id,omitempty"`
+	Accuracy   *float64 `json:"accuracy,omitempty" msgpack:"accuracy,omitempty"`
+	Altitude   *float64 `json:"altitude,omitempty" msgpack:"altitude,omitempty"`
+	Speed      *float64 `json:"speed,omitempty" msgpack:"speed,omitempty"`
+	Bearing    *float64 `json:"bearing,omitempty" msgpack:"bearing,omitempty"`
+	Satellites *int     `json:"satellites,omitempty" msgpack:"satellites,omitempty"`
+	Source     string   `json:"source,omitempty" msgpack:"source,omitempty"`
+	Seq        uint64   `json:"seq,omitempty" msgpack:"seq,omitempty"`
+	TripID     string   `json:"trip_id,omitempty" msgpack:"trip_id,omitempty"`
+}
+
+func newLocationV1(l track.Location) locationV1 {
+	return locationV1{
+		BusID:      l.Bus.ID,
+		Lat:        l.Lat,
+		Long:       l.Long,
+		Timestamp:  l.Timestamp,
+		DriverID:   l.Driver.ID,
+		Accuracy:   l.Accuracy,
+		Altitude:   l.Altitude,
+		Speed:      l.Speed,
+		Bearing:    l.Bearing,
+		Satellites: l.Satellites,
+		Source:     string(l.Source),
+		Seq:        l.Seq,
+		TripID:     l.TripID,
+	}
+}
+
+func (v locationV1) location() track.Location {
+	return track.Location{
+		Long:       v.Long,
+		Lat:        v.Lat,
+		Bus:        track.Bus{ID: v.BusID},
+		Driver:     track.Driver{ID: v.DriverID},
+		Timestamp:  v.Timestamp,
+		Accuracy:   v.Accuracy,
+		Altitude:   v.Altitude,
+		Speed:      v.Speed,
+		Bearing:    v.Bearing,
+		Satellites: v.Satellites,
+		Source:     track.Source(v.Source),
+		Seq:        v.Seq,
+		TripID:     v.TripID,
+	}
+}
+
+// JSON encode location with the version 1 JSON schema.
+type JSON struct{}
+
+func (JSON) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSON) Encode(l track.Location) ([]byte, error) {
+	return json.Marshal(newLocationV1(l))
+}
+
+func (JSON) Decode(b []byte) (track.Location, error) {
+	var v locationV1
+	if err := json.Unmarshal(b, &v); err != nil {
+		return track.Location{}, err
+	}
+	return v.location(), nil
+}
+
+// Legacy decode message produced before the codec is introduced,
+// where the track.Location Go fields are used as the wire schema.
+type Legacy struct{}
+
+func (Legacy) ContentType() string {
+	return ""
+}
+
+func (Legacy) Encode(l track.Location) ([]byte, error) {
+	return json.Marshal(l)
+}
+
+func (Legacy) Decode(b []byte) (track.Location, error) {
+	var l track.Location
+	err := json.Unmarshal(b, &l)
+	return l, err
+}
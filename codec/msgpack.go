@@ -0,0 +1,25 @@
+package codec
+
+import (
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Msgpack encode location with the version 1 schema in MessagePack.
+type Msgpack struct{}
+
+func (Msgpack) ContentType() string {
+	return ContentTypeMsgpack
+}
+
+func (Msgpack) Encode(l track.Location) ([]byte, error) {
+	return msgpack.Marshal(newLocationV1(l))
+}
+
+func (Msgpack) Decode(b []byte) (track.Location, error) {
+	var v locationV1
+	if err := msgpack.Unmarshal(b, &v); err != nil {
+		return track.Location{}, err
+	}
+	return v.location(), nil
+}
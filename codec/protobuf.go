@@ -0,0 +1,106 @@
+package codec
+
+import (
+	trackingv1 "github.com/rafimuhammad01/tracking-app/proto/tracking/v1"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Protobuf encode location with the tracking.v1.Location message.
+type Protobuf struct{}
+
+func (Protobuf) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (Protobuf) Encode(l track.Location) ([]byte, error) {
+	return proto.Marshal(ToProto(l))
+}
+
+func (Protobuf) Decode(b []byte) (track.Location, error) {
+	var v trackingv1.Location
+	if err := proto.Unmarshal(b, &v); err != nil {
+		return track.Location{}, err
+	}
+	return FromProto(&v), nil
+}
+
+// ToProto convert the location to tracking.v1.Location.
+func ToProto(l track.Location) *trackingv1.Location {
+	v := &trackingv1.Location{
+		BusId:     l.Bus.ID,
+		Lat:       l.Lat,
+		Long:      l.Long,
+		Timestamp: timestamppb.New(l.Timestamp),
+		DriverId:  l.Driver.ID,
+		Accuracy:  l.Accuracy,
+		Altitude:  l.Altitude,
+		Speed:     l.Speed,
+		Bearing:   l.Bearing,
+		Source:    string(l.Source),
+		Seq:       l.Seq,
+		TripId:    l.TripID,
+	}
+	if l.Satellites != nil {
+		v.Satellites = proto.Int32(int32(*l.Satellites))
+	}
+	if l.Route != nil {
+		v.Route = &trackingv1.Route{Id: l.Route.ID, ShortName: l.Route.ShortName, LongName: l.Route.LongName}
+	}
+	if l.NextStop != nil {
+		v.NextStop = &trackingv1.Stop{Id: l.NextStop.ID, Name: l.NextStop.Name, Lat: l.NextStop.Lat, Long: l.NextStop.Long}
+	}
+	return v
+}
+
+// FromProto convert tracking.v1.Location to location, a missing timestamp is left as zero time.
+func FromProto(v *trackingv1.Location) track.Location {
+	l := track.Location{
+		Long:   v.GetLong(),
+		Lat:    v.GetLat(),
+		Bus:    track.Bus{ID: v.GetBusId()},
+		Driver: track.Driver{ID: v.GetDriverId()},
+		Source: track.Source(v.GetSource()),
+		Seq:    v.GetSeq(),
+		TripID: v.GetTripId(),
+	}
+	if v.GetTimestamp() != nil {
+		l.Timestamp = v.GetTimestamp().AsTime()
+	}
+	if v.Accuracy != nil {
+		l.Accuracy = proto.Float64(v.GetAccuracy())
+	}
+	if v.Altitude != nil {
+		l.Altitude = proto.Float64(v.GetAltitude())
+	}
+	if v.Speed != nil {
+		l.Speed = proto.Float64(v.GetSpeed())
+	}
+	if v.Bearing != nil {
+		l.Bearing = proto.Float64(v.GetBearing())
+	}
+	if v.Satellites != nil {
+		satellites := int(v.GetSatellites())
+		l.Satellites = &satellites
+	}
+	if r := v.GetRoute(); r != nil {
+		l.Route = &track.Route{ID: r.GetId(), ShortName: r.GetShortName(), LongName: r.GetLongName()}
+	}
+	if s := v.GetNextStop(); s != nil {
+		l.NextStop = &track.Stop{ID: s.GetId(), Name: s.GetName(), Lat: s.GetLat(), Long: s.GetLong()}
+	}
+	return l
+}
+
+// TripToProto convert the trip to tracking.v1.Trip.
+func TripToProto(t track.Trip) *trackingv1.Trip {
+	return &trackingv1.Trip{
+		Id:        t.ID,
+		BusId:     t.Bus.ID,
+		RouteId:   t.RouteID,
+		DriverId:  t.Driver.ID,
+		Status:    string(t.Status),
+		Timestamp: timestamppb.New(t.Timestamp),
+	}
+}
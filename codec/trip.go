@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+// ContentTypeTrip is the content type of the trip event, sent on the same topic as the location.
+// Trip event is always encoded in JSON whatever the location codec is, since it is rare.
+const ContentTypeTrip = "application/vnd.tracking.trip.v1+json"
+
+// tripV1 is the version 1 wire schema of the trip event.
+type tripV1 struct {
+	ID        string    `json:"id"`
+	BusID     string    `json:"bus_id"`
+	RouteID   string    `json:"route_id"`
+	DriverID  string    `json:"driver_id,omitempty"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EncodeTrip encode the trip event with the version 1 JSON schema.
+func EncodeTrip(t track.Trip) ([]byte, error) {
+	return json.Marshal(tripV1{
+		ID:        t.ID,
+		BusID:     t.Bus.ID,
+		RouteID:   t.RouteID,
+		DriverID:  t.Driver.ID,
+		Status:    string(t.Status),
+		Timestamp: t.Timestamp,
+	})
+}
+
+// DecodeTrip decode the trip event encoded by EncodeTrip.
+func DecodeTrip(b []byte) (track.Trip, error) {
+	var v tripV1
+	if err := json.Unmarshal(b, &v); err != nil {
+		return track.Trip{}, err
+	}
+
+	return track.Trip{
+		ID:        v.ID,
+		Bus:       track.Bus{ID: v.BusID},
+		RouteID:   v.RouteID,
+		Driver:    track.Driver{ID: v.DriverID},
+		Status:    track.TripStatus(v.Status),
+		Timestamp: v.Timestamp,
+	}, nil
+}
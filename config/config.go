@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/rs/zerolog/log"
 
 	"github.com/spf13/viper"
@@ -12,12 +14,120 @@ var (
 
 type (
 	Config struct {
-		Kafka Kafka `mapstructure:"kafka"`
-		HTTP  HTTP  `mapstructure:"http"`
-		Debug bool  `mapstructure:"debug"`
+		Broker     Broker     `mapstructure:"broker"`
+		Kafka      Kafka      `mapstructure:"kafka"`
+		NATS       NATS       `mapstructure:"nats"`
+		Redis      Redis      `mapstructure:"redis"`
+		HTTP       HTTP       `mapstructure:"http"`
+		GRPC       GRPC       `mapstructure:"grpc"`
+		WebSocket  WebSocket  `mapstructure:"websocket"`
+		Auth       Auth       `mapstructure:"auth"`
+		Validation Validation `mapstructure:"validation"`
+		Filter     Filter     `mapstructure:"filter"`
+		GTFS       GTFS       `mapstructure:"gtfs"`
+		BusRoutes  []BusRoute `mapstructure:"bus_routes"`
+		Hub        Hub        `mapstructure:"hub"`
+		Cache      Cache      `mapstructure:"cache"`
+		Outbox     Outbox     `mapstructure:"outbox"`
+		Debug      bool       `mapstructure:"debug"`
+	}
+
+	// BusRoute is a list entry rather than a map, viper lowercase the keys of a map and the bus id is case sensitive.
+	BusRoute struct {
+		BusID   string `mapstructure:"bus_id"`
+		RouteID string `mapstructure:"route_id"`
+	}
+
+	Outbox struct {
+		Enabled       bool          `mapstructure:"enabled"`
+		Path          string        `mapstructure:"path"`
+		MaxBytes      int64         `mapstructure:"max_bytes"`
+		Fsync         string        `mapstructure:"fsync"`
+		FsyncInterval time.Duration `mapstructure:"fsync_interval"`
+		RetryInterval time.Duration `mapstructure:"retry_interval"`
+	}
+
+	Cache struct {
+		TTL time.Duration `mapstructure:"ttl"`
+	}
+
+	Hub struct {
+		BufferSize                int    `mapstructure:"buffer_size"`
+		OverflowPolicy            string `mapstructure:"overflow_policy"`
+		MaxConnectionsPerCustomer int    `mapstructure:"max_connections_per_customer"`
+		Shards                    int    `mapstructure:"shards"`
+	}
+
+	Broker struct {
+		Type  string `mapstructure:"type"`
+		Codec string `mapstructure:"codec"`
+	}
+
+	NATS struct {
+		URL     string `mapstructure:"url"`
+		Subject string `mapstructure:"subject"`
+	}
+
+	Redis struct {
+		Addr     string `mapstructure:"addr"`
+		Password string `mapstructure:"password"`
+		DB       int    `mapstructure:"db"`
+		Stream   string `mapstructure:"stream"`
+		MaxLen   int64  `mapstructure:"max_len"`
 	}
 
 	HTTP struct {
+		DriverPort       string        `mapstructure:"driver_port"`
+		TrackerPort      string        `mapstructure:"tracker_port"`
+		MaxBatchSize     int           `mapstructure:"max_batch_size"`
+		DrainTimeout     time.Duration `mapstructure:"drain_timeout"`
+		ReconnectBackoff time.Duration `mapstructure:"reconnect_backoff"`
+	}
+
+	Validation struct {
+		MaxFutureSkew time.Duration `mapstructure:"max_future_skew"`
+		MaxPastSkew   time.Duration `mapstructure:"max_past_skew"`
+	}
+
+	Filter struct {
+		MaxSpeed         float64 `mapstructure:"max_speed"`
+		KalmanNoise      float64 `mapstructure:"kalman_noise"`
+		StationaryRadius float64 `mapstructure:"stationary_radius"`
+		Diagnostics      string  `mapstructure:"diagnostics"`
+	}
+
+	GTFS struct {
+		Path           string        `mapstructure:"path"`
+		ReloadInterval time.Duration `mapstructure:"reload_interval"`
+	}
+
+	Auth struct {
+		Driver AuthToken `mapstructure:"driver"`
+		Rider  RiderAuth `mapstructure:"rider"`
+	}
+
+	RiderAuth struct {
+		AuthToken     `mapstructure:",squash"`
+		SessionCookie string              `mapstructure:"session_cookie"`
+		TenantRoutes  map[string][]string `mapstructure:"tenant_routes"`
+	}
+
+	AuthToken struct {
+		Enabled     bool   `mapstructure:"enabled"`
+		HS256Secret string `mapstructure:"hs256_secret"`
+		JWKSFile    string `mapstructure:"jwks_file"`
+		Issuer      string `mapstructure:"issuer"`
+		Audience    string `mapstructure:"audience"`
+	}
+
+	WebSocket struct {
+		PingInterval time.Duration `mapstructure:"ping_interval"`
+		PongTimeout  time.Duration `mapstructure:"pong_timeout"`
+		WriteTimeout time.Duration `mapstructure:"write_timeout"`
+		MaxIdle      time.Duration `mapstructure:"max_idle"`
+	}
+
+	GRPC struct {
 		DriverPort  string `mapstructure:"driver_port"`
 		TrackerPort string `mapstructure:"tracker_port"`
 	}
@@ -25,6 +135,7 @@ type (
 	Kafka struct {
 		Connection KafkaConnection `mapstructure:"connection"`
 		Consumer   KafkaConsumer   `mapstructure:"consumer"`
+		DeadLetter KafkaDeadLetter `mapstructure:"dead_letter"`
 	}
 
 	KafkaConnection struct {
@@ -38,6 +149,10 @@ type (
 		MaxBytes int    `mapstructure:"max_bytes"`
 		Topic    string `mapstructure:"topic"`
 	}
+
+	KafkaDeadLetter struct {
+		Topic string `mapstructure:"topic"`
+	}
 )
 
 func Get() *Config {
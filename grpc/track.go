@@ -0,0 +1,247 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/codec"
+	trackingv1 "github.com/rafimuhammad01/tracking-app/proto/tracking/v1"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataSessionID is the metadata key identifying the customer, the same as the Session-ID http header.
+	MetadataSessionID = "session-id"
+	// MetadataAuthorization is the metadata key of the bearer token, the same as the Authorization http header.
+	MetadataAuthorization = "authorization"
+)
+
+type TrackingService interface {
+	Send(ctx context.Context, l track.Location) error
+	Register(c track.Customer, s track.Subscription, u chan track.Update) (track.Handle, error)
+	Unregister(h track.Handle)
+	Dropped(c track.Customer) uint64
+	Validate(l track.Location) error
+}
+
+// TrackingServer serve tracking.v1.DriverService and tracking.v1.RiderService.
+type TrackingServer struct {
+	trackingv1.UnimplementedDriverServiceServer
+	trackingv1.UnimplementedRiderServiceServer
+
+	trackingSvc TrackingService
+	driverAuth  *auth.Verifier
+	riderAuth   *auth.Verifier
+}
+
+// PublishLocations send every location streamed by the driver.
+// An invalid location is reported in the response without ending the stream,
+// while a location that can't be sent end the stream so the driver can resend it.
+func (s *TrackingServer) PublishLocations(stream trackingv1.DriverService_PublishLocationsServer) error {
+	driver, err := s.authenticateDriver(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	var resp trackingv1.PublishLocationsResponse
+	for i := uint64(0); ; i++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&resp)
+		}
+		if err != nil {
+			return err
+		}
+
+		loc, err := s.location(req.GetLocation())
+		if err == nil && driver != nil {
+			err = driver.Authorize(&loc)
+		}
+		if err != nil {
+			resp.Rejected++
+			resp.Errors = append(resp.Errors, publishError(i, err))
+			continue
+		}
+
+		// the location will be delivered once the broker recover.
+		if err := s.trackingSvc.Send(stream.Context(), loc); err != nil && !errors.Is(err, track.ErrBuffered) {
+			log.Error().Err(err).Msg("failed to send location")
+			return status.Error(codes.Unavailable, "internal server error")
+		}
+		resp.Accepted++
+	}
+}
+
+// WatchBuses stream the location and the trip event of the requested buses until the rider cancel the call.
+func (s *TrackingServer) WatchBuses(req *trackingv1.WatchBusesRequest, stream trackingv1.RiderService_WatchBusesServer) error {
+	if len(req.GetBusIds()) == 0 {
+		return status.Error(codes.InvalidArgument, "invalid bus_id value")
+	}
+
+	customer, err := s.authenticateRider(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	updates := make(chan track.Update)
+	handle, err := s.trackingSvc.Register(customer, track.Subscription{BusIDs: req.GetBusIds()}, updates)
+	if err != nil {
+		if errors.Is(err, track.ErrTooManyConnections) {
+			return status.Error(codes.ResourceExhausted, "too many connections")
+		}
+		if errors.Is(err, track.ErrNotAllowed) {
+			return status.Error(codes.PermissionDenied, "bus not allowed")
+		}
+		log.Error().Err(err).Msg("error when register client")
+		return status.Error(codes.Internal, "internal server error")
+	}
+	log.Debug().Any("customer", customer).Any("bus_ids", req.GetBusIds()).Msg("client registered")
+	defer func() {
+		log.Debug().Any("customer", customer).Uint64("dropped", s.trackingSvc.Dropped(customer)).Msg("client unregistered")
+		s.trackingSvc.Unregister(handle)
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case u, ok := <-updates:
+			// the hub close the channel when it evict a client that can't keep up.
+			if !ok {
+				log.Info().Any("customer", customer).Msg("client evicted as slow consumer")
+				return status.Error(codes.ResourceExhausted, "slow consumer")
+			}
+
+			var resp trackingv1.WatchBusesResponse
+			if u.Trip != nil {
+				resp.Trip = codec.TripToProto(*u.Trip)
+			} else {
+				resp.Location = codec.ToProto(*u.Location)
+			}
+			if err := stream.Send(&resp); err != nil {
+				log.Error().Err(err).Msg("grpc send error")
+				return err
+			}
+		}
+	}
+}
+
+// location validate the location sent by the driver, a missing timestamp means now.
+func (s *TrackingServer) location(v *trackingv1.Location) (track.Location, error) {
+	if v == nil {
+		return track.Location{}, errors.New("invalid location value")
+	}
+
+	loc := codec.FromProto(v)
+	// the trip, the route, the next stop and the driver are set by the server, never by the device.
+	loc.TripID, loc.Route, loc.NextStop, loc.Driver = "", nil, nil, track.Driver{}
+	if v.GetTimestamp() == nil {
+		loc.Timestamp = time.Now()
+	}
+	return loc, s.trackingSvc.Validate(loc)
+}
+
+func publishError(i uint64, err error) *trackingv1.PublishError {
+	e := &trackingv1.PublishError{Index: i, Error: err.Error()}
+	var ve *track.ValidationError
+	if errors.As(err, &ve) {
+		for _, f := range ve.Fields {
+			e.Fields = append(e.Fields, &trackingv1.FieldError{Field: f.Field, Message: f.Message})
+		}
+	}
+	return e
+}
+
+// authenticateDriver verify the token of the authorization metadata.
+// It returns nil driver when driver authentication is not enabled.
+func (s *TrackingServer) authenticateDriver(ctx context.Context) (*auth.Driver, error) {
+	if s.driverAuth == nil {
+		return nil, nil
+	}
+
+	token, _ := strings.CutPrefix(metadataValue(ctx, MetadataAuthorization), "Bearer ")
+	d, err := s.driverAuth.VerifyDriver(token)
+	if err != nil {
+		log.Debug().Err(err).Msg("driver authentication failed")
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &d, nil
+}
+
+// authenticateRider returns the customer of the call.
+// When rider authentication is not enabled, the customer is identified by the session-id metadata.
+func (s *TrackingServer) authenticateRider(ctx context.Context) (track.Customer, error) {
+	if s.riderAuth == nil {
+		return track.Customer{ID: sessionID(ctx)}, nil
+	}
+
+	token, _ := strings.CutPrefix(metadataValue(ctx, MetadataAuthorization), "Bearer ")
+	c, err := s.riderAuth.VerifyRider(token)
+	if err != nil {
+		log.Debug().Err(err).Msg("rider authentication failed")
+		return track.Customer{}, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return c, nil
+}
+
+func sessionID(ctx context.Context) string {
+	return metadataValue(ctx, MetadataSessionID)
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// RegisterDriverService register the driver-service rpc to srv.
+func RegisterDriverService(srv *grpc.Server, s *TrackingServer) {
+	trackingv1.RegisterDriverServiceServer(srv, s)
+}
+
+// RegisterRiderService register the tracking-service rpc to srv.
+func RegisterRiderService(srv *grpc.Server, s *TrackingServer) {
+	trackingv1.RegisterRiderServiceServer(srv, s)
+}
+
+func NewServer(trackingSvc TrackingService, opts ...opts) *TrackingServer {
+	s := &TrackingServer{
+		trackingSvc: trackingSvc,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+type opts func(*TrackingServer)
+
+// WithRiderAuth require the rider rpc to be called with a token verified by v.
+func WithRiderAuth(v *auth.Verifier) opts {
+	return func(s *TrackingServer) {
+		s.riderAuth = v
+	}
+}
+
+// WithDriverAuth require the driver rpc to be called with a token verified by v.
+func WithDriverAuth(v *auth.Verifier) opts {
+	return func(s *TrackingServer) {
+		s.driverAuth = v
+	}
+}
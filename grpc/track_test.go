@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	trackingv1 "github.com/rafimuhammad01/tracking-app/proto/tracking/v1"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []track.Location
+}
+
+func (f *fakeSender) Send(ctx context.Context, l track.Location) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, l)
+	return nil
+}
+
+func dial(t *testing.T, tracker *track.Tracker) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	RegisterDriverService(srv, NewServer(tracker))
+	RegisterRiderService(srv, NewServer(tracker))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestPublishLocations(t *testing.T) {
+	sender := &fakeSender{}
+	conn := dial(t, track.NewTracker(track.WithSender(sender)))
+
+	stream, err := trackingv1.NewDriverServiceClient(conn).PublishLocations(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&trackingv1.PublishLocationsRequest{Location: &trackingv1.Location{BusId: "1", Lat: 1, Long: 1, TripId: "t1", DriverId: "d1", Route: &trackingv1.Route{Id: "r1"}, NextStop: &trackingv1.Stop{Id: "s1"}}}))
+	require.NoError(t, stream.Send(&trackingv1.PublishLocationsRequest{Location: &trackingv1.Location{Lat: 2, Long: 2}}))
+	require.NoError(t, stream.Send(&trackingv1.PublishLocationsRequest{}))
+
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.GetAccepted())
+	assert.Equal(t, uint64(2), resp.GetRejected())
+	require.Len(t, resp.GetErrors(), 2)
+	assert.Equal(t, uint64(1), resp.GetErrors()[0].GetIndex())
+	assert.Equal(t, "invalid location: bus_id: is required", resp.GetErrors()[0].GetError())
+	assert.Equal(t, "bus_id", resp.GetErrors()[0].GetFields()[0].GetField())
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, "1", sender.sent[0].Bus.ID)
+	assert.False(t, sender.sent[0].Timestamp.IsZero())
+	// the fields set by the server are not taken from the device.
+	assert.Empty(t, sender.sent[0].TripID)
+	assert.Empty(t, sender.sent[0].Driver.ID)
+	assert.Nil(t, sender.sent[0].Route)
+	assert.Nil(t, sender.sent[0].NextStop)
+}
+
+func TestWatchBuses(t *testing.T) {
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithCache(0))
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.Receive(track.Location{Bus: track.Bus{ID: "1"}, Lat: 1, Long: 1, Timestamp: ts})
+	conn := dial(t, tracker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := trackingv1.NewRiderServiceClient(conn).WatchBuses(ctx, &trackingv1.WatchBusesRequest{BusIds: []string{"1"}})
+	require.NoError(t, err)
+
+	// the last known location is sent first.
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "1", resp.GetLocation().GetBusId())
+	assert.Equal(t, ts, resp.GetLocation().GetTimestamp().AsTime())
+
+	tracker.Receive(track.Location{Bus: track.Bus{ID: "2"}, Lat: 2, Long: 2, Timestamp: ts})
+	tracker.Receive(track.Location{Bus: track.Bus{ID: "1"}, Lat: 3, Long: 3, Timestamp: ts.Add(time.Second)})
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "1", resp.GetLocation().GetBusId())
+	assert.Equal(t, float64(3), resp.GetLocation().GetLat())
+}
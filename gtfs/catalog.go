@@ -0,0 +1,132 @@
+package gtfs
+
+import (
+	"context"
+	"expvar"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultReloadInterval = time.Minute
+
+// stats exposes the gtfs counters through expvar (/debug/vars).
+var stats = expvar.NewMap("gtfs")
+
+// Catalog keeps the feed of the GTFS zip at path, and reload it when the file changes.
+// A feed that fails to load is ignored and the previous one is kept.
+type Catalog struct {
+	path     string
+	interval time.Duration
+
+	feed atomic.Pointer[Feed]
+
+	mu      sync.Mutex // serialize reload
+	modTime time.Time
+	size    int64
+}
+
+type opts func(*Catalog)
+
+// NewCatalog load the GTFS zip at path, an error is returned when it can't be loaded.
+func NewCatalog(path string, opts ...opts) (*Catalog, error) {
+	c := Catalog{
+		path:     path,
+		interval: defaultReloadInterval,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if _, err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// WithReloadInterval will check the file for change every d, one minute by default.
+func WithReloadInterval(d time.Duration) opts {
+	return func(c *Catalog) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// Feed returns the feed currently loaded.
+func (c *Catalog) Feed() *Feed {
+	return c.feed.Load()
+}
+
+// Reload load the file again when its modification time or size changed since the last load,
+// and report whether the feed is replaced.
+func (c *Catalog) Reload() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return false, err
+	}
+	if c.feed.Load() != nil && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return false, nil
+	}
+
+	f, err := Load(c.path)
+	if err != nil {
+		return false, err
+	}
+
+	c.feed.Store(f)
+	c.modTime, c.size = info.ModTime(), info.Size()
+	return true, nil
+}
+
+// Watch reload the file when it changes until ctx is done.
+func (c *Catalog) Watch(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := c.Reload()
+			if err != nil {
+				stats.Add("reload_failures", 1)
+				log.Error().Err(err).Str("path", c.path).Msg("failed to reload gtfs feed, keeping the previous one")
+				continue
+			}
+			if reloaded {
+				stats.Add("reloads", 1)
+				log.Info().Str("path", c.path).Msg("gtfs feed reloaded")
+			}
+		}
+	}
+}
+
+// Enrich add the names of the route and the next stop of the bus to the location.
+// The location is returned as is when its route is unknown to the feed.
+func (c *Catalog) Enrich(l track.Location) track.Location {
+	if l.Route == nil {
+		return l
+	}
+
+	f := c.Feed()
+	r, ok := f.Route(l.Route.ID)
+	if !ok {
+		return l
+	}
+	l.Route = &track.Route{ID: r.ID, ShortName: r.ShortName, LongName: r.LongName}
+
+	if s, ok := f.NextStop(r.ID, l.Lat, l.Long, l.Bearing); ok {
+		l.NextStop = &track.Stop{ID: s.ID, Name: s.Name, Lat: s.Lat, Long: s.Long}
+	}
+	return l
+}
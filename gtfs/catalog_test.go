@@ -0,0 +1,65 @@
+package gtfs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogReload(t *testing.T) {
+	path := writeFeed(t, testFeed)
+	c, err := NewCatalog(path)
+	require.NoError(t, err)
+
+	reloaded, err := c.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// the file is replaced with a new version of the feed.
+	updated := writeFeed(t, map[string]string{
+		"routes.txt":     "route_id,route_short_name\nr2,2\n",
+		"stops.txt":      testFeed["stops.txt"],
+		"trips.txt":      "route_id,trip_id\nr2,t1\n",
+		"stop_times.txt": "trip_id,stop_id,stop_sequence\nt1,A,1\n",
+	})
+	require.NoError(t, os.Rename(updated, path))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	reloaded, err = c.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	_, ok := c.Feed().Route("r2")
+	assert.True(t, ok)
+
+	// a broken file doesn't replace the loaded feed.
+	require.NoError(t, os.WriteFile(path, []byte("not a zip"), 0o644))
+	_, err = c.Reload()
+	assert.Error(t, err)
+	_, ok = c.Feed().Route("r2")
+	assert.True(t, ok)
+
+	_, err = NewCatalog(path)
+	assert.Error(t, err)
+}
+
+func TestCatalogEnrich(t *testing.T) {
+	c, err := NewCatalog(writeFeed(t, testFeed))
+	require.NoError(t, err)
+
+	east := 90.0
+	l := c.Enrich(track.Location{Lat: 0, Long: 0.005, Bearing: &east, Route: &track.Route{ID: "r1"}})
+	assert.Equal(t, &track.Route{ID: "r1", ShortName: "1", LongName: "Airport - Downtown"}, l.Route)
+	assert.Equal(t, &track.Stop{ID: "B", Name: "Market", Lat: 0, Long: 0.01}, l.NextStop)
+
+	// the bus is not on a trip or its route is not in the feed.
+	l = c.Enrich(track.Location{Long: 0.005})
+	assert.Nil(t, l.NextStop)
+	l = c.Enrich(track.Location{Long: 0.005, Route: &track.Route{ID: "r2"}})
+	assert.Equal(t, &track.Route{ID: "r2"}, l.Route)
+	assert.Nil(t, l.NextStop)
+}
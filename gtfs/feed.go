@@ -0,0 +1,416 @@
+// Package gtfs load the routes and stops of a GTFS static feed and keep them in memory,
+// to add the route and the next stop of the bus to the broadcast location.
+package gtfs
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route denotes a row of routes.txt.
+type Route struct {
+	ID        string
+	AgencyID  string
+	ShortName string
+	LongName  string
+	Type      int
+	Color     string
+}
+
+// Stop denotes a row of stops.txt.
+type Stop struct {
+	ID   string
+	Name string
+	Lat  float64
+	Long float64
+}
+
+// Trip denotes a row of trips.txt.
+type Trip struct {
+	ID          string
+	RouteID     string
+	ServiceID   string
+	Headsign    string
+	DirectionID int
+	ShapeID     string
+}
+
+// StopTime denotes a row of stop_times.txt. Arrival and Departure are the time since the start of
+// the service day, they can go past 24h and are zero when not given.
+type StopTime struct {
+	TripID    string
+	StopID    string
+	Sequence  int
+	Arrival   time.Duration
+	Departure time.Duration
+}
+
+// ShapePoint denotes a row of shapes.txt.
+type ShapePoint struct {
+	Lat      float64
+	Long     float64
+	Sequence int
+}
+
+// Feed is the content of a GTFS static feed. It is never modified once loaded.
+type Feed struct {
+	routes    map[string]Route
+	stops     map[string]Stop
+	trips     map[string]Trip
+	stopTimes map[string][]StopTime   // trip id -> stop times ordered by sequence
+	shapes    map[string][]ShapePoint // shape id -> points ordered by sequence
+
+	// patterns is the stops served by every route, one pattern per direction.
+	patterns map[string][]pattern
+}
+
+// Load read the GTFS zip at path.
+// routes.txt, stops.txt, trips.txt and stop_times.txt are required, shapes.txt is optional.
+func Load(path string) (*Feed, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return Parse(&zr.Reader)
+}
+
+// Parse read the GTFS files of the zip.
+func Parse(zr *zip.Reader) (*Feed, error) {
+	f := Feed{
+		routes:    make(map[string]Route),
+		stops:     make(map[string]Stop),
+		trips:     make(map[string]Trip),
+		stopTimes: make(map[string][]StopTime),
+		shapes:    make(map[string][]ShapePoint),
+	}
+
+	files := []struct {
+		name     string
+		required bool
+		parse    func(row) error
+	}{
+		{"routes.txt", true, f.parseRoute},
+		{"stops.txt", true, f.parseStop},
+		{"trips.txt", true, f.parseTrip},
+		{"stop_times.txt", true, f.parseStopTime},
+		{"shapes.txt", false, f.parseShapePoint},
+	}
+	for _, file := range files {
+		if err := readFile(zr, file.name, file.required, file.parse); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sts := range f.stopTimes {
+		sort.Slice(sts, func(i, j int) bool { return sts[i].Sequence < sts[j].Sequence })
+	}
+	for _, pts := range f.shapes {
+		sort.Slice(pts, func(i, j int) bool { return pts[i].Sequence < pts[j].Sequence })
+	}
+	f.patterns = f.buildPatterns()
+
+	return &f, nil
+}
+
+func (f *Feed) parseRoute(r row) error {
+	id, err := r.required("route_id")
+	if err != nil {
+		return err
+	}
+	typ, err := r.int("route_type", false)
+	if err != nil {
+		return err
+	}
+
+	f.routes[id] = Route{
+		ID:        id,
+		AgencyID:  r.get("agency_id"),
+		ShortName: r.get("route_short_name"),
+		LongName:  r.get("route_long_name"),
+		Type:      typ,
+		Color:     r.get("route_color"),
+	}
+	return nil
+}
+
+func (f *Feed) parseStop(r row) error {
+	id, err := r.required("stop_id")
+	if err != nil {
+		return err
+	}
+	// station and entrance don't always have a position, they are not served by any trip.
+	lat, err := r.float("stop_lat")
+	if err != nil {
+		return err
+	}
+	long, err := r.float("stop_lon")
+	if err != nil {
+		return err
+	}
+
+	f.stops[id] = Stop{ID: id, Name: r.get("stop_name"), Lat: lat, Long: long}
+	return nil
+}
+
+func (f *Feed) parseTrip(r row) error {
+	id, err := r.required("trip_id")
+	if err != nil {
+		return err
+	}
+	routeID, err := r.required("route_id")
+	if err != nil {
+		return err
+	}
+	if _, ok := f.routes[routeID]; !ok {
+		return fmt.Errorf("unknown route_id %q", routeID)
+	}
+	direction, err := r.int("direction_id", false)
+	if err != nil {
+		return err
+	}
+
+	f.trips[id] = Trip{
+		ID:          id,
+		RouteID:     routeID,
+		ServiceID:   r.get("service_id"),
+		Headsign:    r.get("trip_headsign"),
+		DirectionID: direction,
+		ShapeID:     r.get("shape_id"),
+	}
+	return nil
+}
+
+func (f *Feed) parseStopTime(r row) error {
+	tripID, err := r.required("trip_id")
+	if err != nil {
+		return err
+	}
+	if _, ok := f.trips[tripID]; !ok {
+		return fmt.Errorf("unknown trip_id %q", tripID)
+	}
+	stopID, err := r.required("stop_id")
+	if err != nil {
+		return err
+	}
+	if _, ok := f.stops[stopID]; !ok {
+		return fmt.Errorf("unknown stop_id %q", stopID)
+	}
+	seq, err := r.int("stop_sequence", true)
+	if err != nil {
+		return err
+	}
+	arrival, err := r.time("arrival_time")
+	if err != nil {
+		return err
+	}
+	departure, err := r.time("departure_time")
+	if err != nil {
+		return err
+	}
+
+	f.stopTimes[tripID] = append(f.stopTimes[tripID], StopTime{
+		TripID:    tripID,
+		StopID:    stopID,
+		Sequence:  seq,
+		Arrival:   arrival,
+		Departure: departure,
+	})
+	return nil
+}
+
+func (f *Feed) parseShapePoint(r row) error {
+	id, err := r.required("shape_id")
+	if err != nil {
+		return err
+	}
+	lat, err := r.float("shape_pt_lat")
+	if err != nil {
+		return err
+	}
+	long, err := r.float("shape_pt_lon")
+	if err != nil {
+		return err
+	}
+	seq, err := r.int("shape_pt_sequence", true)
+	if err != nil {
+		return err
+	}
+
+	f.shapes[id] = append(f.shapes[id], ShapePoint{Lat: lat, Long: long, Sequence: seq})
+	return nil
+}
+
+// Route returns the route by its id.
+func (f *Feed) Route(id string) (Route, bool) {
+	r, ok := f.routes[id]
+	return r, ok
+}
+
+// Routes returns every route ordered by id.
+func (f *Feed) Routes() []Route {
+	routes := make([]Route, 0, len(f.routes))
+	for _, r := range f.routes {
+		routes = append(routes, r)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].ID < routes[j].ID })
+	return routes
+}
+
+// Stop returns the stop by its id.
+func (f *Feed) Stop(id string) (Stop, bool) {
+	s, ok := f.stops[id]
+	return s, ok
+}
+
+// Trip returns the trip by its id.
+func (f *Feed) Trip(id string) (Trip, bool) {
+	t, ok := f.trips[id]
+	return t, ok
+}
+
+// StopTimes returns the stop times of the trip ordered by sequence.
+func (f *Feed) StopTimes(tripID string) []StopTime {
+	return f.stopTimes[tripID]
+}
+
+// Shape returns the points of the shape ordered by sequence.
+func (f *Feed) Shape(id string) []ShapePoint {
+	return f.shapes[id]
+}
+
+// RouteStops returns the stops served by the route in the direction, in order.
+func (f *Feed) RouteStops(routeID string, direction int) []Stop {
+	for _, p := range f.patterns[routeID] {
+		if p.direction == direction {
+			return p.stops
+		}
+	}
+	return nil
+}
+
+// row is a record of a GTFS file, its fields are found by the header name.
+type row struct {
+	header map[string]int
+	record []string
+}
+
+func (r row) get(name string) string {
+	i, ok := r.header[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r row) required(name string) (string, error) {
+	v := r.get(name)
+	if v == "" {
+		return "", fmt.Errorf("%s is required", name)
+	}
+	return v, nil
+}
+
+func (r row) float(name string) (float64, error) {
+	v := r.get(name)
+	if v == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return f, nil
+}
+
+func (r row) int(name string, required bool) (int, error) {
+	v := r.get(name)
+	if v == "" {
+		if required {
+			return 0, fmt.Errorf("%s is required", name)
+		}
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return i, nil
+}
+
+// time parse HH:MM:SS, the hour can be more than 24 for trip going past midnight.
+func (r row) time(name string) (time.Duration, error) {
+	v := r.get(name)
+	if v == "" {
+		return 0, nil
+	}
+
+	parts := strings.Split(v, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 || (i > 0 && n > 59) {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+		d += time.Duration(n) * unit
+	}
+	return d, nil
+}
+
+// readFile call parse for every record of the file, the error is prefixed with the file name and the line.
+func readFile(zr *zip.Reader, name string, required bool, parse func(row) error) error {
+	file, err := zr.Open(name)
+	if err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	defer file.Close()
+
+	cr := csv.NewReader(file)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	names, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: missing header", name)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	header := make(map[string]int, len(names))
+	for i, n := range names {
+		if i == 0 {
+			n = strings.TrimPrefix(n, "\ufeff")
+		}
+		header[strings.TrimSpace(n)] = i
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		if err := parse(row{header: header, record: record}); err != nil {
+			line, _ := cr.FieldPos(0)
+			return fmt.Errorf("%s: line %d: %w", name, line, err)
+		}
+	}
+}
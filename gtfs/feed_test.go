@@ -0,0 +1,162 @@
+package gtfs
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFeed has route r1 going east from A to C, and back west.
+var testFeed = map[string]string{
+	"routes.txt": "\ufeffroute_id,route_short_name,route_long_name,route_type\n" +
+		"r1,1,Airport - Downtown,3\n",
+	"stops.txt": "stop_id,stop_name,stop_lat,stop_lon\n" +
+		"A,Airport,0,0\n" +
+		"B,Market,0,0.01\n" +
+		"C,Downtown,0,0.02\n",
+	"trips.txt": "route_id,service_id,trip_id,direction_id,shape_id\n" +
+		"r1,weekday,t1,0,sh1\n" +
+		"r1,weekday,t1-short,0,sh1\n" +
+		"r1,weekday,t2,1,\n",
+	"stop_times.txt": "trip_id,arrival_time,departure_time,stop_id,stop_sequence\n" +
+		"t1,08:00:00,08:00:00,A,1\n" +
+		"t1,08:10:00,08:11:00,C,3\n" +
+		"t1,08:05:00,08:05:30,B,2\n" +
+		"t1-short,25:00:00,25:00:00,A,1\n" +
+		"t1-short,,,B,2\n" +
+		"t2,09:00:00,09:00:00,C,1\n" +
+		"t2,09:05:00,09:05:00,B,2\n" +
+		"t2,09:10:00,09:10:00,A,3\n",
+	"shapes.txt": "shape_id,shape_pt_lat,shape_pt_lon,shape_pt_sequence\n" +
+		"sh1,0,0.02,2\n" +
+		"sh1,0,0,1\n",
+}
+
+// writeFeed write the files as a GTFS zip in a temporary directory and returns its path.
+func writeFeed(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "gtfs.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load(writeFeed(t, testFeed))
+	require.NoError(t, err)
+
+	r, ok := f.Route("r1")
+	require.True(t, ok)
+	assert.Equal(t, Route{ID: "r1", ShortName: "1", LongName: "Airport - Downtown", Type: 3}, r)
+	assert.Equal(t, []Route{r}, f.Routes())
+
+	s, ok := f.Stop("B")
+	require.True(t, ok)
+	assert.Equal(t, Stop{ID: "B", Name: "Market", Lat: 0, Long: 0.01}, s)
+
+	tr, ok := f.Trip("t2")
+	require.True(t, ok)
+	assert.Equal(t, 1, tr.DirectionID)
+
+	sts := f.StopTimes("t1")
+	require.Len(t, sts, 3)
+	assert.Equal(t, []string{"A", "B", "C"}, []string{sts[0].StopID, sts[1].StopID, sts[2].StopID})
+	assert.Equal(t, 8*time.Hour+5*time.Minute+30*time.Second, sts[1].Departure)
+	assert.Equal(t, 25*time.Hour, f.StopTimes("t1-short")[0].Arrival)
+
+	assert.Equal(t, []ShapePoint{{Lat: 0, Long: 0, Sequence: 1}, {Lat: 0, Long: 0.02, Sequence: 2}}, f.Shape("sh1"))
+
+	// the longest trip of every direction gives the stops of the route.
+	ids := func(stops []Stop) []string {
+		var ids []string
+		for _, s := range stops {
+			ids = append(ids, s.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"A", "B", "C"}, ids(f.RouteStops("r1", 0)))
+	assert.Equal(t, []string{"C", "B", "A"}, ids(f.RouteStops("r1", 1)))
+	assert.Empty(t, f.RouteStops("r2", 0))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	with := func(name, content string) map[string]string {
+		files := make(map[string]string, len(testFeed))
+		for k, v := range testFeed {
+			files[k] = v
+		}
+		if content == "" {
+			delete(files, name)
+		} else {
+			files[name] = content
+		}
+		return files
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{"missing stops", with("stops.txt", ""), "stops.txt: open stops.txt: file does not exist"},
+		{"invalid latitude", with("stops.txt", "stop_id,stop_lat,stop_lon\nA,0,0\nB,north,0\n"), "stops.txt: line 3: invalid stop_lat \"north\""},
+		{"unknown stop", with("stop_times.txt", "trip_id,stop_id,stop_sequence\nt1,D,1\n"), "stop_times.txt: line 2: unknown stop_id \"D\""},
+		{"invalid time", with("stop_times.txt", "trip_id,arrival_time,stop_id,stop_sequence\nt1,8:61:00,A,1\n"), "stop_times.txt: line 2: invalid arrival_time \"8:61:00\""},
+		{"missing sequence", with("stop_times.txt", "trip_id,stop_id\nt1,A\n"), "stop_times.txt: line 2: stop_sequence is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeFeed(t, tt.files))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+
+	// shapes.txt is optional.
+	_, err := Load(writeFeed(t, with("shapes.txt", "")))
+	assert.NoError(t, err)
+}
+
+func TestNextStop(t *testing.T) {
+	f, err := Load(writeFeed(t, testFeed))
+	require.NoError(t, err)
+
+	east, west := 90.0, 270.0
+	tests := []struct {
+		name    string
+		long    float64
+		bearing *float64
+		want    string
+	}{
+		{"going east between A and B", 0.005, &east, "B"},
+		{"going west between B and A", 0.005, &west, "A"},
+		{"going east between B and C", 0.015, &east, "C"},
+		{"unknown bearing", 0.015, nil, "C"},
+		{"before the first stop", -0.005, &east, "A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, ok := f.NextStop("r1", 0.0001, tt.long, tt.bearing)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, s.ID)
+		})
+	}
+
+	_, ok := f.NextStop("r2", 0, 0, nil)
+	assert.False(t, ok)
+}
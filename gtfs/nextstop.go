@@ -0,0 +1,127 @@
+package gtfs
+
+import (
+	"math"
+	"sort"
+)
+
+const earthRadius = 6371008.8 // mean earth radius in meter
+
+// pattern is the ordered stops a route serves in one direction,
+// taken from the trip of the route with the most stops in that direction.
+type pattern struct {
+	direction int
+	stops     []Stop
+}
+
+func (f *Feed) buildPatterns() map[string][]pattern {
+	type key struct {
+		route     string
+		direction int
+	}
+	longest := make(map[key]string)
+	for id, tr := range f.trips {
+		k := key{tr.RouteID, tr.DirectionID}
+		cur, ok := longest[k]
+		n, m := len(f.stopTimes[id]), len(f.stopTimes[cur])
+		// the trip id breaks the tie so the same feed always gives the same pattern.
+		if !ok || n > m || (n == m && id < cur) {
+			longest[k] = id
+		}
+	}
+
+	patterns := make(map[string][]pattern)
+	for k, tripID := range longest {
+		sts := f.stopTimes[tripID]
+		if len(sts) == 0 {
+			continue
+		}
+		stops := make([]Stop, len(sts))
+		for i, st := range sts {
+			stops[i] = f.stops[st.StopID]
+		}
+		patterns[k.route] = append(patterns[k.route], pattern{direction: k.direction, stops: stops})
+	}
+	for _, ps := range patterns {
+		sort.Slice(ps, func(i, j int) bool { return ps[i].direction < ps[j].direction })
+	}
+	return patterns
+}
+
+// NextStop returns the next stop of a bus at lat, long serving the route.
+// The bus is placed on the closest segment between two consecutive stops of the route, its next stop is the end of the segment.
+// When the bearing is known, only the direction whose segment goes the same way as the bus is considered,
+// unless none of them does.
+func (f *Feed) NextStop(routeID string, lat, long float64, bearing *float64) (Stop, bool) {
+	var (
+		best, fallback         Stop
+		bestDist, fallbackDist = math.Inf(1), math.Inf(1)
+	)
+	for _, p := range f.patterns[routeID] {
+		if len(p.stops) == 1 {
+			if d := planeDistance(lat, long, p.stops[0]); d < fallbackDist {
+				fallback, fallbackDist = p.stops[0], d
+			}
+			continue
+		}
+
+		for i := 0; i < len(p.stops)-1; i++ {
+			from, to := p.stops[i], p.stops[i+1]
+			d, t, heading := project(lat, long, from, to)
+
+			next := to
+			// the bus has not reached the first stop yet.
+			if i == 0 && t <= 0 {
+				next = from
+			}
+
+			if d < fallbackDist {
+				fallback, fallbackDist = next, d
+			}
+			if bearing != nil && angle(heading, *bearing) <= 90 && d < bestDist {
+				best, bestDist = next, d
+			}
+		}
+	}
+
+	if !math.IsInf(bestDist, 1) {
+		return best, true
+	}
+	return fallback, !math.IsInf(fallbackDist, 1)
+}
+
+// project returns the distance in meter from the position to the segment between from and to,
+// where the closest point is on the segment from 0 (from) to 1 (to), and the heading of the segment in degree.
+// The earth is taken as flat around the position, which is accurate enough between two stops.
+func project(lat, long float64, from, to Stop) (dist, t, heading float64) {
+	ax, ay := plane(lat, long, from)
+	bx, by := plane(lat, long, to)
+	dx, dy := bx-ax, by-ay
+
+	heading = math.Mod(math.Atan2(dx, dy)*180/math.Pi+360, 360)
+	if l := dx*dx + dy*dy; l > 0 {
+		// the position is the origin of the plane.
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	px, py := ax+t*dx, ay+t*dy
+	return math.Hypot(px, py), t, heading
+}
+
+func planeDistance(lat, long float64, s Stop) float64 {
+	return math.Hypot(plane(lat, long, s))
+}
+
+// plane returns the position of the stop in meter east and north of lat, long.
+func plane(lat, long float64, s Stop) (x, y float64) {
+	const rad = math.Pi / 180
+	return (s.Long - long) * rad * earthRadius * math.Cos(lat*rad), (s.Lat - lat) * rad * earthRadius
+}
+
+// angle returns the difference between two headings in degree, from 0 to 180.
+func angle(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		return 360 - d
+	}
+	return d
+}
@@ -0,0 +1,62 @@
+package gtfs
+
+import (
+	"time"
+
+	gtfsrt "github.com/rafimuhammad01/tracking-app/proto/transit_realtime"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"google.golang.org/protobuf/proto"
+)
+
+// realtimeVersion is the version of the GTFS Realtime specification of the feed.
+const realtimeVersion = "2.0"
+
+// VehiclePositions build the GTFS Realtime feed with the position of every bus, created at now.
+// The trip descriptor only has the route id of the bus, the trip started by the driver
+// is not a trip of the static feed so its id is never sent.
+func VehiclePositions(locs []track.Location, now time.Time) *gtfsrt.FeedMessage {
+	feed := &gtfsrt.FeedMessage{
+		Header: &gtfsrt.FeedHeader{
+			GtfsRealtimeVersion: proto.String(realtimeVersion),
+			Incrementality:      gtfsrt.FeedHeader_FULL_DATASET.Enum(),
+			Timestamp:           proto.Uint64(uint64(now.Unix())),
+		},
+		Entity: make([]*gtfsrt.FeedEntity, 0, len(locs)),
+	}
+
+	for _, l := range locs {
+		feed.Entity = append(feed.Entity, &gtfsrt.FeedEntity{
+			Id:      proto.String(l.Bus.ID),
+			Vehicle: vehiclePosition(l),
+		})
+	}
+	return feed
+}
+
+func vehiclePosition(l track.Location) *gtfsrt.VehiclePosition {
+	v := &gtfsrt.VehiclePosition{
+		Vehicle: &gtfsrt.VehicleDescriptor{Id: proto.String(l.Bus.ID)},
+		Position: &gtfsrt.Position{
+			Latitude:  proto.Float32(float32(l.Lat)),
+			Longitude: proto.Float32(float32(l.Long)),
+		},
+	}
+	if l.Bearing != nil {
+		v.Position.Bearing = proto.Float32(float32(*l.Bearing))
+	}
+	if l.Speed != nil {
+		v.Position.Speed = proto.Float32(float32(*l.Speed))
+	}
+	if !l.Timestamp.IsZero() {
+		v.Timestamp = proto.Uint64(uint64(l.Timestamp.Unix()))
+	}
+
+	if l.Route != nil {
+		v.Trip = &gtfsrt.TripDescriptor{RouteId: proto.String(l.Route.ID)}
+	}
+	if l.NextStop != nil {
+		v.StopId = proto.String(l.NextStop.ID)
+		v.CurrentStatus = gtfsrt.VehiclePosition_IN_TRANSIT_TO.Enum()
+	}
+	return v
+}
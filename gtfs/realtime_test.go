@@ -0,0 +1,55 @@
+package gtfs
+
+import (
+	"testing"
+	"time"
+
+	gtfsrt "github.com/rafimuhammad01/tracking-app/proto/transit_realtime"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVehiclePositions(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	speed, bearing := 12.5, 90.0
+	feed := VehiclePositions([]track.Location{
+		{
+			Lat: -6.5, Long: 106.5, Bus: track.Bus{ID: "1"}, Timestamp: now.Add(-time.Second),
+			Speed: &speed, Bearing: &bearing, TripID: "t1",
+			Route: &track.Route{ID: "r1"}, NextStop: &track.Stop{ID: "B"},
+		},
+		{Lat: 1, Long: 2, Bus: track.Bus{ID: "2"}},
+	}, now)
+
+	// the feed must be valid for any consumer, with every required field set.
+	b, err := proto.Marshal(feed)
+	require.NoError(t, err)
+	var got gtfsrt.FeedMessage
+	require.NoError(t, proto.Unmarshal(b, &got))
+
+	assert.Equal(t, "2.0", got.GetHeader().GetGtfsRealtimeVersion())
+	assert.Equal(t, gtfsrt.FeedHeader_FULL_DATASET, got.GetHeader().GetIncrementality())
+	assert.Equal(t, uint64(now.Unix()), got.GetHeader().GetTimestamp())
+	require.Len(t, got.GetEntity(), 2)
+
+	v := got.GetEntity()[0].GetVehicle()
+	assert.Equal(t, "1", got.GetEntity()[0].GetId())
+	assert.Equal(t, "1", v.GetVehicle().GetId())
+	assert.Equal(t, float32(-6.5), v.GetPosition().GetLatitude())
+	assert.Equal(t, float32(106.5), v.GetPosition().GetLongitude())
+	assert.Equal(t, float32(12.5), v.GetPosition().GetSpeed())
+	assert.Equal(t, float32(90), v.GetPosition().GetBearing())
+	assert.Equal(t, uint64(now.Unix()-1), v.GetTimestamp())
+	assert.Nil(t, v.GetTrip().TripId)
+	assert.Equal(t, "r1", v.GetTrip().GetRouteId())
+	assert.Equal(t, "B", v.GetStopId())
+	assert.Equal(t, gtfsrt.VehiclePosition_IN_TRANSIT_TO, v.GetCurrentStatus())
+
+	v = got.GetEntity()[1].GetVehicle()
+	assert.Nil(t, v.GetTrip())
+	assert.Nil(t, v.Timestamp)
+	assert.Nil(t, v.Position.Speed)
+	assert.Nil(t, v.StopId)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+// authenticateDriver verify the bearer token of the driver.
+// It returns nil driver when driver authentication is not enabled.
+func (s *TrackingHandler) authenticateDriver(r *http.Request) (*auth.Driver, error) {
+	if s.driverAuth == nil {
+		return nil, nil
+	}
+
+	d, err := s.driverAuth.VerifyDriver(auth.BearerToken(r))
+	if err != nil {
+		log.Debug().Err(err).Msg("driver authentication failed")
+		return nil, err
+	}
+	return &d, nil
+}
+
+// authenticateRider returns the customer of the request.
+// When rider authentication is not enabled, the customer is identified by the Session-ID header.
+func (s *TrackingHandler) authenticateRider(r *http.Request) (track.Customer, error) {
+	if s.riderAuth == nil {
+		return track.Customer{ID: r.Header.Get("Session-ID")}, nil
+	}
+
+	c, err := s.riderAuth.VerifyRider(auth.RequestToken(r, s.sessionCookie))
+	if err != nil {
+		log.Debug().Err(err).Msg("rider authentication failed")
+		return track.Customer{}, err
+	}
+	return c, nil
+}
+
+// authorizeLocation check the driver is allowed to send the location and record the driver on it.
+func authorizeLocation(d *auth.Driver, l *track.Location) error {
+	if d == nil {
+		return nil
+	}
+	return d.Authorize(l)
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	msg := "invalid token"
+	if errors.Is(err, auth.ErrMissingToken) {
+		msg = "missing token"
+	}
+	writeJSON(w, http.StatusUnauthorized, Response{Error: msg})
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendLocationDriverAuth(t *testing.T) {
+	secret := []byte("secret")
+	v, err := auth.NewVerifier(auth.Config{HS256Secret: secret})
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     "driver-1",
+		"bus_ids": []string{"1"},
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		token  string
+		busID  string
+		status int
+	}{
+		{name: "missing token", busID: "1", status: http.StatusUnauthorized},
+		{name: "invalid token", token: "abc", busID: "1", status: http.StatusUnauthorized},
+		{name: "bus not allowed", token: token, busID: "2", status: http.StatusForbidden},
+		{name: "allowed", token: token, busID: "1", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeTrackingService{}
+			h := NewHandler(svc, WithDriverAuth(v))
+
+			req := httptest.NewRequest(http.MethodPost, "/location?bus_id="+tt.busID, strings.NewReader(`{"long": 1, "lat": 1}`))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.SendLocation(rec, req)
+
+			require.Equal(t, tt.status, rec.Code)
+			if tt.status == http.StatusOK {
+				require.Len(t, svc.sent, 1)
+				assert.Equal(t, "driver-1", svc.sent[0].Driver.ID)
+			}
+		})
+	}
+}
+
+func TestGetBusLocationRiderAuth(t *testing.T) {
+	secret := []byte("secret")
+	v, err := auth.NewVerifier(auth.Config{HS256Secret: secret})
+	require.NoError(t, err)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":     "rider-1",
+		"bus_ids": []string{"1"},
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(secret)
+	require.NoError(t, err)
+
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithCache(0))
+	tracker.Receive(track.Location{Bus: track.Bus{ID: "1"}})
+	tracker.Receive(track.Location{Bus: track.Bus{ID: "2"}})
+	h := NewHandler(tracker, WithRiderAuth(v, "session"))
+
+	tests := []struct {
+		name   string
+		busID  string
+		header string
+		cookie string
+		status int
+	}{
+		{name: "missing token", busID: "1", status: http.StatusUnauthorized},
+		{name: "bearer token", busID: "1", header: "Bearer " + token, status: http.StatusOK},
+		{name: "session cookie", busID: "1", cookie: token, status: http.StatusOK},
+		{name: "bus not allowed", busID: "2", cookie: token, status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/buses/"+tt.busID+"/location", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			h.GetBusLocation(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxBatchSize = 1000
+
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+var errBatchTooLarge = errors.New("batch too large")
+
+// BatchResponse denotes the result of a batch request.
+type BatchResponse struct {
+	Accepted int         `json:"accepted"`
+	Rejected int         `json:"rejected"`
+	Errors   []ItemError `json:"errors,omitempty"`
+}
+
+// ItemError denotes why a location in the batch is rejected, Index is its position in the request.
+type ItemError struct {
+	Index  int                `json:"index"`
+	Error  string             `json:"error"`
+	Fields []track.FieldError `json:"fields,omitempty"`
+}
+
+// SendLocationBatch serve POST /locations/batch.
+// The body is either a JSON array or newline delimited JSON (Content-Type: application/x-ndjson),
+// every location is validated on its own and the invalid one is reported without rejecting the rest.
+func (d *TrackingHandler) SendLocationBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	driver, err := d.authenticateDriver(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+
+	var reqs []json.RawMessage
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == contentTypeNDJSON {
+		reqs, err = decodeNDJSON(r.Body, d.maxBatchSize)
+	} else {
+		reqs, err = decodeJSONArray(r.Body, d.maxBatchSize)
+	}
+	if err != nil {
+		if errors.Is(err, errBatchTooLarge) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, Response{Error: "batch too large"})
+			return
+		}
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid request body"})
+		return
+	}
+
+	locs, resp := d.parseBatch(reqs, r.URL.Query().Get("bus_id"), driver)
+	if len(locs) == 0 {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "no valid location", Data: resp})
+		return
+	}
+
+	// send location
+	if err := d.trackingSvc.SendBatch(r.Context(), locs); err != nil {
+		// the location will be delivered once the broker recover.
+		if errors.Is(err, track.ErrBuffered) {
+			writeJSON(w, http.StatusAccepted, Response{Data: resp})
+			return
+		}
+		log.Error().Err(err).Int("size", len(locs)).Msg("failed to send location batch")
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: resp})
+}
+
+// parseBatch validate every location in the batch.
+// The valid locations are ordered by their timestamp, so location of the same bus is published in order.
+func (d *TrackingHandler) parseBatch(reqs []json.RawMessage, busID string, driver *auth.Driver) ([]track.Location, BatchResponse) {
+	var resp BatchResponse
+	locs := make([]track.Location, 0, len(reqs))
+	for i, raw := range reqs {
+		var req locationRequest
+		if err := json.Unmarshal(raw, &req); err != nil {
+			resp.Errors = append(resp.Errors, ItemError{Index: i, Error: "invalid location value"})
+			continue
+		}
+
+		loc, err := d.location(req, busID)
+		if err == nil {
+			err = authorizeLocation(driver, &loc)
+		}
+		if err != nil {
+			resp.Errors = append(resp.Errors, newItemError(i, err))
+			continue
+		}
+		locs = append(locs, loc)
+	}
+
+	sort.SliceStable(locs, func(i, j int) bool {
+		return locs[i].Timestamp.Before(locs[j].Timestamp)
+	})
+
+	resp.Accepted = len(locs)
+	resp.Rejected = len(resp.Errors)
+	return locs, resp
+}
+
+func newItemError(i int, err error) ItemError {
+	e := ItemError{Index: i, Error: err.Error()}
+	var ve *track.ValidationError
+	if errors.As(err, &ve) {
+		e.Fields = ve.Fields
+	}
+	return e
+}
+
+func decodeJSONArray(r io.Reader, max int) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := t.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected json array")
+	}
+
+	var reqs []json.RawMessage
+	for dec.More() {
+		if len(reqs) == max {
+			return nil, errBatchTooLarge
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, raw)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
+
+func decodeNDJSON(r io.Reader, max int) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+
+	var reqs []json.RawMessage
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return reqs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(reqs) == max {
+			return nil, errBatchTooLarge
+		}
+		reqs = append(reqs, raw)
+	}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTrackingService struct {
+	TrackingService
+	mu      sync.Mutex
+	sent    []track.Location
+	batches [][]track.Location
+	err     error
+
+	unregistered chan track.Handle
+}
+
+func (f *fakeTrackingService) Register(c track.Customer, s track.Subscription, u chan track.Update) (track.Handle, error) {
+	return track.Handle{Customer: c}, nil
+}
+
+func (f *fakeTrackingService) Unregister(h track.Handle) {
+	f.unregistered <- h
+}
+
+func (f *fakeTrackingService) Validate(l track.Location) error {
+	return track.NewTracker().Validate(l)
+}
+
+func (f *fakeTrackingService) Dropped(c track.Customer) uint64 {
+	return 0
+}
+
+func (f *fakeTrackingService) Send(ctx context.Context, l track.Location) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, l)
+	return f.err
+}
+
+func (f *fakeTrackingService) SendBatch(ctx context.Context, locs []track.Location) error {
+	f.batches = append(f.batches, locs)
+	return f.err
+}
+
+func TestSendLocationBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		err         error
+		status      int
+		accepted    int
+		errors      []ItemError
+	}{
+		{
+			name:        "json array",
+			contentType: "application/json",
+			body: `[
+				{"long": 1, "lat": 1, "timestamp": "2023-01-01T00:00:02Z"},
+				{"long": 2, "lat": 2, "timestamp": "2023-01-01T00:00:01Z"},
+				{"long": 3, "lat": 3, "timestamp": "yesterday"}
+			]`,
+			status:   http.StatusOK,
+			accepted: 2,
+			errors:   []ItemError{{Index: 2, Error: "invalid location: timestamp: must be in RFC3339 format", Fields: []track.FieldError{{Field: "timestamp", Message: "must be in RFC3339 format"}}}},
+		},
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body: `{"long": 1, "lat": 1, "timestamp": "2023-01-01T00:00:02Z"}
+{"long": 2, "lat": 2, "timestamp": "2023-01-01T00:00:01Z"}
+`,
+			status:   http.StatusOK,
+			accepted: 2,
+		},
+		{
+			name:        "no valid location",
+			contentType: "application/json",
+			body:        `[{"long": 1, "lat": 1, "timestamp": "yesterday"}]`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `[{"long": 1}, {"long": 2}, {"long": 3}, {"long": 4}]`,
+			status:      http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "buffered",
+			contentType: "application/json",
+			body:        `[{"long": 1, "lat": 1}]`,
+			err:         track.ErrBuffered,
+			status:      http.StatusAccepted,
+			accepted:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeTrackingService{err: tt.err}
+			h := NewHandler(svc, WithMaxBatchSize(3))
+
+			req := httptest.NewRequest(http.MethodPost, "/locations/batch?bus_id=1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			h.SendLocationBatch(rec, req)
+
+			require.Equal(t, tt.status, rec.Code)
+			if tt.accepted == 0 {
+				return
+			}
+
+			var resp struct {
+				Data BatchResponse `json:"data"`
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tt.accepted, resp.Data.Accepted)
+			assert.Equal(t, tt.errors, resp.Data.Errors)
+
+			// the batch is ordered by timestamp.
+			require.Len(t, svc.batches, 1)
+			locs := svc.batches[0]
+			for i := 1; i < len(locs); i++ {
+				assert.False(t, locs[i].Timestamp.Before(locs[i-1].Timestamp))
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+// control message sent by the rider over the websocket.
+const (
+	controlSubscribe   = "subscribe"
+	controlUnsubscribe = "unsubscribe"
+	controlSetRate     = "set_rate"
+	controlPing        = "ping"
+)
+
+// message sent by the server over the websocket.
+const (
+	frameLocation     = "location"
+	frameTrip         = "trip"
+	frameSubscribed   = "subscribed"
+	frameUnsubscribed = "unsubscribed"
+	frameRateSet      = "rate_set"
+	framePong         = "pong"
+)
+
+// maxRateInterval is the longest interval accepted by set_rate.
+const maxRateInterval = time.Minute
+
+// controlRequest denotes a control message sent by the rider.
+// ID is chosen by the rider and echoed back in the reply.
+type controlRequest struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id,omitempty"`
+	BusIDs     []string `json:"bus_ids,omitempty"`
+	RouteIDs   []string `json:"route_ids,omitempty"`
+	IntervalMS int64    `json:"interval_ms,omitempty"`
+}
+
+// ControlResponse denotes the reply of a control message.
+type ControlResponse struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id,omitempty"`
+	BusIDs     []string `json:"bus_ids,omitempty"`
+	RouteIDs   []string `json:"route_ids,omitempty"`
+	IntervalMS *int64   `json:"interval_ms,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// LocationFrame denotes a location sent over the rider websocket.
+type LocationFrame struct {
+	Type string `json:"type"`
+	LocationResponse
+}
+
+// TripFrame denotes a trip event sent over the rider websocket.
+type TripFrame struct {
+	Type string `json:"type"`
+	TripResponse
+}
+
+// riderSession keep the state of a rider websocket connection.
+// It is only used by the goroutine writing to the connection.
+type riderSession struct {
+	handle track.Handle
+	buses  []string
+	follow map[string]struct{}
+	// a bus joining one of the routes is followed once its first update is received.
+	routes      []string
+	followRoute map[string]struct{}
+
+	// when interval is set, only the latest location of every bus is sent once per interval.
+	interval time.Duration
+	ticker   *time.Ticker
+	pending  map[string]track.Location
+}
+
+func newRiderSession(h track.Handle, busIDs []string) *riderSession {
+	s := &riderSession{
+		handle:      h,
+		follow:      make(map[string]struct{}),
+		followRoute: make(map[string]struct{}),
+		pending:     make(map[string]track.Location),
+	}
+	s.add(busIDs)
+	return s
+}
+
+// control apply the control message and returns its reply.
+func (s *TrackingHandler) control(sess *riderSession, msg []byte) ControlResponse {
+	var req controlRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return ControlResponse{Type: frameError, Error: "invalid message"}
+	}
+
+	switch req.Type {
+	case controlSubscribe, controlUnsubscribe:
+		if len(req.BusIDs) == 0 && len(req.RouteIDs) == 0 {
+			return ControlResponse{Type: frameError, ID: req.ID, Error: "invalid bus_ids value"}
+		}
+
+		if req.Type == controlSubscribe {
+			if len(req.BusIDs) > 0 {
+				if err := s.trackingSvc.Subscribe(sess.handle, req.BusIDs); err != nil {
+					return controlError(req, err)
+				}
+				sess.add(req.BusIDs)
+			}
+			// the route stays followed, a bus joining it later is added by the tracker.
+			if len(req.RouteIDs) > 0 {
+				busIDs, err := s.trackingSvc.SubscribeRoutes(sess.handle, req.RouteIDs)
+				if err != nil {
+					return controlError(req, err)
+				}
+				sess.add(busIDs)
+				sess.addRoutes(req.RouteIDs)
+			}
+			return ControlResponse{Type: frameSubscribed, ID: req.ID, BusIDs: sess.buses, RouteIDs: sess.routes}
+		}
+
+		if len(req.BusIDs) > 0 {
+			if err := s.trackingSvc.Unsubscribe(sess.handle, req.BusIDs); err != nil {
+				return controlError(req, err)
+			}
+			sess.remove(req.BusIDs)
+		}
+		if len(req.RouteIDs) > 0 {
+			busIDs, err := s.trackingSvc.UnsubscribeRoutes(sess.handle, req.RouteIDs)
+			if err != nil {
+				return controlError(req, err)
+			}
+			sess.remove(busIDs)
+			sess.removeRoutes(req.RouteIDs)
+		}
+		return ControlResponse{Type: frameUnsubscribed, ID: req.ID, BusIDs: sess.buses, RouteIDs: sess.routes}
+	case controlSetRate:
+		interval := time.Duration(req.IntervalMS) * time.Millisecond
+		if interval < 0 || interval > maxRateInterval {
+			return ControlResponse{Type: frameError, ID: req.ID, Error: "invalid interval_ms value"}
+		}
+		sess.setRate(interval)
+		return ControlResponse{Type: frameRateSet, ID: req.ID, IntervalMS: &req.IntervalMS}
+	case controlPing:
+		return ControlResponse{Type: framePong, ID: req.ID}
+	default:
+		return ControlResponse{Type: frameError, ID: req.ID, Error: "unknown message type"}
+	}
+}
+
+func controlError(req controlRequest, err error) ControlResponse {
+	if errors.Is(err, track.ErrUnknownHandle) {
+		return ControlResponse{Type: frameError, ID: req.ID, Error: "connection closed"}
+	}
+	if errors.Is(err, track.ErrNotAllowed) {
+		return ControlResponse{Type: frameError, ID: req.ID, Error: "bus not allowed"}
+	}
+	log.Error().Err(err).Str("type", req.Type).Msg("failed to apply control message")
+	return ControlResponse{Type: frameError, ID: req.ID, Error: "internal server error"}
+}
+
+func (s *riderSession) add(busIDs []string) {
+	for _, id := range busIDs {
+		if _, ok := s.follow[id]; ok || id == "" {
+			continue
+		}
+		s.follow[id] = struct{}{}
+		s.buses = append(s.buses, id)
+	}
+}
+
+func (s *riderSession) remove(busIDs []string) {
+	for _, id := range busIDs {
+		delete(s.follow, id)
+		delete(s.pending, id)
+	}
+
+	buses := make([]string, 0, len(s.follow))
+	for _, id := range s.buses {
+		if _, ok := s.follow[id]; ok {
+			buses = append(buses, id)
+		}
+	}
+	s.buses = buses
+}
+
+func (s *riderSession) addRoutes(routeIDs []string) {
+	for _, id := range routeIDs {
+		if _, ok := s.followRoute[id]; ok || id == "" {
+			continue
+		}
+		s.followRoute[id] = struct{}{}
+		s.routes = append(s.routes, id)
+	}
+}
+
+func (s *riderSession) removeRoutes(routeIDs []string) {
+	for _, id := range routeIDs {
+		delete(s.followRoute, id)
+	}
+
+	routes := make([]string, 0, len(s.followRoute))
+	for _, id := range s.routes {
+		if _, ok := s.followRoute[id]; ok {
+			routes = append(routes, id)
+		}
+	}
+	s.routes = routes
+}
+
+// follows report whether the update should be sent,
+// update of an unsubscribed bus can still be buffered by the hub.
+// The bus of an update on a followed route is followed from now on, the tracker added it to the connection.
+func (s *riderSession) follows(u track.Update) bool {
+	busID := u.BusID()
+	if _, ok := s.follow[busID]; ok {
+		return true
+	}
+
+	var routeID string
+	switch {
+	case u.Trip != nil && u.Trip.Status != track.TripEnded:
+		routeID = u.Trip.RouteID
+	case u.Location != nil && u.Location.Route != nil:
+		routeID = u.Location.Route.ID
+	}
+	if _, ok := s.followRoute[routeID]; !ok || routeID == "" {
+		return false
+	}
+	s.add([]string{busID})
+	return true
+}
+
+func (s *riderSession) setRate(interval time.Duration) {
+	s.interval = interval
+	if s.ticker != nil {
+		s.ticker.Stop()
+		s.ticker = nil
+	}
+	if interval > 0 {
+		s.ticker = time.NewTicker(interval)
+	}
+}
+
+// tick returns the channel firing when the pending location should be sent,
+// it is nil and never fire when the rate is not limited.
+func (s *riderSession) tick() <-chan time.Time {
+	if s.ticker == nil {
+		return nil
+	}
+	return s.ticker.C
+}
+
+// flush returns the pending location ordered by timestamp.
+func (s *riderSession) flush() []track.Location {
+	locs := make([]track.Location, 0, len(s.pending))
+	for id, l := range s.pending {
+		locs = append(locs, l)
+		delete(s.pending, id)
+	}
+	sort.Slice(locs, func(i, j int) bool {
+		return locs[i].Timestamp.Before(locs[j].Timestamp)
+	})
+	return locs
+}
+
+func (s *riderSession) close() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlProtocol(t *testing.T) {
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithRouteLookup(track.BusRoutes{"3": "r1"}))
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(tracker).GetLatestLocation))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/location?bus_id=1"
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	control := func(req string, want ControlResponse) {
+		t.Helper()
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte(req)))
+
+		var resp ControlResponse
+		require.NoError(t, c.ReadJSON(&resp))
+		assert.Equal(t, want, resp)
+	}
+	readLocation := func() LocationFrame {
+		t.Helper()
+		var f LocationFrame
+		require.NoError(t, c.ReadJSON(&f))
+		return f
+	}
+
+	// the reply of ping also tells the connection is registered.
+	control(`{"type": "ping", "id": "a"}`, ControlResponse{Type: framePong, ID: "a"})
+	control(`{"type": "subscribe", "id": "b", "bus_ids": ["2"]}`, ControlResponse{Type: frameSubscribed, ID: "b", BusIDs: []string{"1", "2"}})
+	control(`{"type": "subscribe", "route_ids": ["r1"]}`, ControlResponse{Type: frameSubscribed, BusIDs: []string{"1", "2", "3"}, RouteIDs: []string{"r1"}})
+	control(`{"type": "unsubscribe", "route_ids": ["r1"]}`, ControlResponse{Type: frameUnsubscribed, BusIDs: []string{"1", "2"}})
+
+	// no bus serves r2 yet, a bus starting a trip on it is followed.
+	control(`{"type": "subscribe", "route_ids": ["r2"]}`, ControlResponse{Type: frameSubscribed, BusIDs: []string{"1", "2"}, RouteIDs: []string{"r2"}})
+	tracker.ReceiveTrip(track.Trip{ID: "t1", Bus: track.Bus{ID: "4"}, RouteID: "r2", Status: track.TripStarted})
+	var trip TripFrame
+	require.NoError(t, c.ReadJSON(&trip))
+	assert.Equal(t, frameTrip, trip.Type)
+	assert.Equal(t, "4", trip.BusID)
+	tracker.Receive(track.Location{Lat: 6, Bus: track.Bus{ID: "4"}})
+	assert.Equal(t, float64(6), readLocation().Lat)
+	control(`{"type": "unsubscribe", "bus_ids": ["4"], "route_ids": ["r2"]}`, ControlResponse{Type: frameUnsubscribed, BusIDs: []string{"1", "2"}})
+	control(`{"type": "subscribe"}`, ControlResponse{Type: frameError, Error: "invalid bus_ids value"})
+	control(`{"type": "dance"}`, ControlResponse{Type: frameError, Error: "unknown message type"})
+
+	tracker.Receive(track.Location{Lat: 1, Bus: track.Bus{ID: "2"}})
+	f := readLocation()
+	assert.Equal(t, frameLocation, f.Type)
+	assert.Equal(t, "2", f.BusID)
+
+	control(`{"type": "unsubscribe", "bus_ids": ["1"]}`, ControlResponse{Type: frameUnsubscribed, BusIDs: []string{"2"}})
+	tracker.Receive(track.Location{Lat: 2, Bus: track.Bus{ID: "1"}})
+	tracker.Receive(track.Location{Lat: 3, Bus: track.Bus{ID: "2"}})
+	assert.Equal(t, float64(3), readLocation().Lat)
+
+	// only the latest location of the interval is sent.
+	interval := int64(200)
+	control(`{"type": "set_rate", "interval_ms": 200}`, ControlResponse{Type: frameRateSet, IntervalMS: &interval})
+	control(`{"type": "set_rate", "interval_ms": -1}`, ControlResponse{Type: frameError, Error: "invalid interval_ms value"})
+	tracker.Receive(track.Location{Lat: 4, Bus: track.Bus{ID: "2"}, Timestamp: time.Now()})
+	tracker.Receive(track.Location{Lat: 5, Bus: track.Bus{ID: "2"}, Timestamp: time.Now()})
+	assert.Equal(t, float64(5), readLocation().Lat)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultReconnectBackoff = 5 * time.Second
+
+// drainer keep track of the streaming connection, so they can be closed on shutdown.
+// http.Server.Shutdown doesn't wait for hijacked connection and wait forever for streaming response.
+type drainer struct {
+	mu       sync.Mutex
+	draining bool
+	active   int
+	shutdown chan struct{} // closed when draining start
+	idle     chan struct{} // closed when draining and no connection is left
+}
+
+func newDrainer() drainer {
+	return drainer{
+		shutdown: make(chan struct{}),
+		idle:     make(chan struct{}),
+	}
+}
+
+// acquire register a new connection, it returns false once draining has started.
+func (d *drainer) acquire() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.draining {
+		return false
+	}
+	d.active++
+	return true
+}
+
+func (d *drainer) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.active--
+	if d.draining && d.active == 0 {
+		close(d.idle)
+	}
+}
+
+// Drain stop accepting streaming connection and ask the connected client to reconnect elsewhere.
+// It returns once every connection is closed, or with the ctx error when ctx is done first.
+func (s *TrackingHandler) Drain(ctx context.Context) error {
+	s.drain.mu.Lock()
+	if !s.drain.draining {
+		s.drain.draining = true
+		close(s.drain.shutdown)
+		if s.drain.active == 0 {
+			close(s.drain.idle)
+		}
+	}
+	s.drain.mu.Unlock()
+
+	select {
+	case <-s.drain.idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reconnectAfter returns the backoff suggested to a client closed by the drain,
+// it is randomized so the clients don't reconnect at the same time.
+func (s *TrackingHandler) reconnectAfter() time.Duration {
+	half := int64(s.reconnectBackoff / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// reconnectReason is the reason of the close message sent on drain.
+func reconnectReason(after time.Duration) string {
+	b, _ := json.Marshal(struct {
+		ReconnectAfterMS int64 `json:"reconnect_after_ms"`
+	}{after.Milliseconds()})
+	return string(b)
+}
+
+func writeDraining(w http.ResponseWriter, after time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(after.Round(time.Second)/time.Second)))
+	writeJSON(w, http.StatusServiceUnavailable, Response{Error: "server is shutting down"})
+}
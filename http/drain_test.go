@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}))
+	h := NewHandler(tracker, WithReconnectBackoff(2*time.Second))
+	srv := httptest.NewServer(http.HandlerFunc(h.GetLatestLocation))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/location?bus_id=1"
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	drained := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		drained <- h.Drain(ctx)
+	}()
+
+	_, _, err = c.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.True(t, errors.As(err, &closeErr), err)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+
+	var reason struct {
+		ReconnectAfterMS int64 `json:"reconnect_after_ms"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(closeErr.Text), &reason))
+	assert.GreaterOrEqual(t, reason.ReconnectAfterMS, int64(1000))
+	assert.LessOrEqual(t, reason.ReconnectAfterMS, int64(2000))
+
+	require.NoError(t, <-drained)
+
+	// no new connection is accepted while draining.
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
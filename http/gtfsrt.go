@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/gtfs"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const contentTypeGTFSRealtime = "application/x-protobuf"
+
+// GetVehiclePositions serve GET /gtfs-rt/vehicle-positions, the GTFS Realtime feed built from the latest known location of every bus.
+// The feed is encoded with protobuf, or with JSON for debugging when format=json is given.
+// Only the buses the rider is allowed to follow are included.
+func (s *TrackingHandler) GetVehiclePositions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "protobuf" {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "format must be json or protobuf"})
+		return
+	}
+
+	customer, err := s.authenticateRider(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+
+	var locs []track.Location
+	for _, l := range s.trackingSvc.LastLocations() {
+		if s.trackingSvc.Authorize(customer, []string{l.Bus.ID}) == nil {
+			locs = append(locs, l)
+		}
+	}
+	feed := gtfs.VehiclePositions(locs, time.Now())
+
+	contentType := contentTypeGTFSRealtime
+	var b []byte
+	if format == "json" {
+		contentType = "application/json"
+		b, err = protojson.MarshalOptions{Multiline: true}.Marshal(feed)
+	} else {
+		b, err = proto.Marshal(feed)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal vehicle positions")
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write(b)
+}
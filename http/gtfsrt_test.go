@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rafimuhammad01/tracking-app/auth"
+	gtfsrt "github.com/rafimuhammad01/tracking-app/proto/transit_realtime"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetVehiclePositions(t *testing.T) {
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithCache(0))
+	tracker.ReceiveTrip(track.Trip{ID: "t1", Bus: track.Bus{ID: "1"}, RouteID: "r1", Status: track.TripStarted})
+	tracker.Receive(track.Location{Lat: 1, Long: 2, Bus: track.Bus{ID: "1"}})
+	tracker.Receive(track.Location{Lat: 3, Long: 4, Bus: track.Bus{ID: "2"}})
+
+	secret := []byte("secret")
+	v, err := auth.NewVerifier(auth.Config{HS256Secret: secret})
+	require.NoError(t, err)
+	sign := func(claims jwt.MapClaims) string {
+		claims["sub"] = "c1"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		require.NoError(t, err)
+		return token
+	}
+
+	h := NewHandler(tracker, WithRiderAuth(v, ""))
+	mux := http.NewServeMux()
+	RegisterTrackerRoutes(mux, h)
+
+	get := func(target, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/gtfs-rt/vehicle-positions", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// bus 1 is allowed since it is on a trip of route r1.
+	rec = get("/gtfs-rt/vehicle-positions", sign(jwt.MapClaims{"bus_ids": []string{"2"}, "route_ids": []string{"r1"}}))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-protobuf", rec.Header().Get("Content-Type"))
+
+	var feed gtfsrt.FeedMessage
+	require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &feed))
+	require.Len(t, feed.GetEntity(), 2)
+	assert.Equal(t, "1", feed.GetEntity()[0].GetId())
+	assert.Nil(t, feed.GetEntity()[0].GetVehicle().GetTrip().TripId)
+	assert.Equal(t, "r1", feed.GetEntity()[0].GetVehicle().GetTrip().GetRouteId())
+	assert.Equal(t, float32(3), feed.GetEntity()[1].GetVehicle().GetPosition().GetLatitude())
+
+	// the bus the rider is not allowed to follow is left out.
+	rec = get("/gtfs-rt/vehicle-positions?format=json", sign(jwt.MapClaims{"bus_ids": []string{"2"}}))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var debug struct {
+		Header struct {
+			Version string `json:"gtfsRealtimeVersion"`
+		} `json:"header"`
+		Entity []struct {
+			ID string `json:"id"`
+		} `json:"entity"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &debug))
+	assert.Equal(t, "2.0", debug.Header.Version)
+	require.Len(t, debug.Entity, 1)
+	assert.Equal(t, "2", debug.Entity[0].ID)
+
+	rec = get("/gtfs-rt/vehicle-positions?format=xml", sign(jwt.MapClaims{"bus_ids": []string{"2"}}))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
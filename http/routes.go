@@ -0,0 +1,20 @@
+package http
+
+import "net/http"
+
+// RegisterDriverRoutes register the driver-service endpoints to mux.
+func RegisterDriverRoutes(mux *http.ServeMux, h *TrackingHandler) {
+	mux.HandleFunc("/location", h.SendLocation)
+	mux.HandleFunc("/location/stream", h.StreamLocation)
+	mux.HandleFunc("/locations/batch", h.SendLocationBatch)
+	mux.HandleFunc("/trips", h.StartTrip)
+	mux.HandleFunc("/trips/", h.UpdateTrip)
+}
+
+// RegisterTrackerRoutes register the tracking-service endpoints to mux.
+func RegisterTrackerRoutes(mux *http.ServeMux, h *TrackingHandler) {
+	mux.HandleFunc("/location", h.GetLatestLocation)
+	mux.HandleFunc("/location/events", h.GetLocationEvents)
+	mux.HandleFunc("/buses/", h.GetBusLocation)
+	mux.HandleFunc("/gtfs-rt/vehicle-positions", h.GetVehiclePositions)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+// sseKeepAlive is how often a comment is sent on an idle stream,
+// so proxies between us and the client don't close it.
+const sseKeepAlive = 15 * time.Second
+
+// GetLocationEvents serve /location/events, the Server-Sent Events fallback of GetLatestLocation
+// for clients that can't upgrade to websocket.
+// The id of every location event is the cursor of the connection, the timestamp of the last location sent
+// of every bus, a reconnecting client sending it back in Last-Event-ID only receive the cached location
+// of a bus newer than its own timestamp. Trip event is sent without id.
+func (s *TrackingHandler) GetLocationEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "streaming unsupported"})
+		return
+	}
+
+	busIDs := parseBusIDs(r.URL.Query())
+	if len(busIDs) == 0 {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid bus_id value"})
+		return
+	}
+
+	cursor, err := parseEventCursor(r.Header.Get("Last-Event-ID"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid Last-Event-ID value"})
+		return
+	}
+	sub := track.Subscription{BusIDs: busIDs, Since: cursor}
+
+	if !s.drain.acquire() {
+		writeDraining(w, s.reconnectAfter())
+		return
+	}
+	defer s.drain.release()
+
+	// setup customer
+	customer, err := s.authenticateRider(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+	updates := make(chan track.Update)
+
+	handle, err := s.trackingSvc.Register(customer, sub, updates)
+	if err != nil {
+		if errors.Is(err, track.ErrTooManyConnections) {
+			writeJSON(w, http.StatusTooManyRequests, Response{Error: "too many connections"})
+			return
+		}
+		if errors.Is(err, track.ErrNotAllowed) {
+			writeJSON(w, http.StatusForbidden, Response{Error: "bus not allowed"})
+			return
+		}
+		log.Error().Err(err).Msg("error when register client")
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
+		return
+	}
+	log.Debug().Any("customer", customer).Any("bus_ids", busIDs).Msg("event stream client registered")
+	defer func() {
+		log.Debug().Any("customer", customer).Uint64("dropped", s.trackingSvc.Dropped(customer)).Msg("event stream client unregistered")
+		s.trackingSvc.Unregister(handle)
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Debug().Any("customer", customer).Msg("event stream closed by client")
+			return
+		case <-s.drain.shutdown:
+			// EventSource reconnect by itself after the retry delay.
+			log.Debug().Any("customer", customer).Msg("event stream closed on shutdown")
+			fmt.Fprintf(w, "retry: %d\n\n", s.reconnectAfter().Milliseconds())
+			flusher.Flush()
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case u, ok := <-updates:
+			// the hub close the channel when it evict a client that can't keep up.
+			if !ok {
+				log.Info().Any("customer", customer).Msg("client evicted as slow consumer")
+				fmt.Fprint(w, "event: error\ndata: {\"error\":\"slow consumer\"}\n\n")
+				flusher.Flush()
+				return
+			}
+
+			// trip event has no id, so it doesn't change the position the client resume from.
+			if u.Trip != nil {
+				b, err := json.Marshal(newTripResponse(*u.Trip))
+				if err != nil {
+					log.Error().Err(err).Msg("failed to marshal trip")
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: trip\ndata: %s\n\n", b); err != nil {
+					log.Error().Err(err).Msg("event stream write error")
+					return
+				}
+				flusher.Flush()
+				continue
+			}
+
+			l := *u.Location
+			b, err := json.Marshal(newLocationResponse(l))
+			if err != nil {
+				log.Error().Err(err).Msg("failed to marshal location")
+				continue
+			}
+			cursor[l.Bus.ID] = l.Timestamp
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: location\ndata: %s\n\n", encodeEventCursor(cursor), b); err != nil {
+				log.Error().Err(err).Msg("event stream write error")
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// parseEventCursor parse the Last-Event-ID of a reconnecting client, the bus id and the timestamp
+// in unix nano of its last location encoded as a query string.
+// The single timestamp sent as id by the previous version is ignored, the client receives every location again.
+func parseEventCursor(id string) (map[string]time.Time, error) {
+	cursor := make(map[string]time.Time)
+	if id == "" {
+		return cursor, nil
+	}
+	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+		return cursor, nil
+	}
+
+	values, err := url.ParseQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	for busID, v := range values {
+		ns, err := strconv.ParseInt(v[0], 10, 64)
+		if err != nil || busID == "" {
+			return nil, fmt.Errorf("invalid cursor of bus %q", busID)
+		}
+		cursor[busID] = time.Unix(0, ns)
+	}
+	return cursor, nil
+}
+
+// encodeEventCursor returns the id of a location event, the timestamp of every bus of the cursor.
+func encodeEventCursor(cursor map[string]time.Time) string {
+	values := make(url.Values, len(cursor))
+	for busID, ts := range cursor {
+		values.Set(busID, strconv.FormatInt(ts.UnixNano(), 10))
+	}
+	return values.Encode()
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLocationEvents(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithCache(0))
+	tracker.Receive(track.Location{Lat: 1, Bus: track.Bus{ID: "1"}, Timestamp: now.Add(time.Second)})
+	tracker.Receive(track.Location{Lat: 2, Bus: track.Bus{ID: "2"}, Timestamp: now})
+
+	cursor := func(bus1, bus2 time.Time) string {
+		return "1=" + strconv.FormatInt(bus1.UnixNano(), 10) + "&2=" + strconv.FormatInt(bus2.UnixNano(), 10)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(tracker).GetLocationEvents))
+	defer srv.Close()
+
+	// the client already received the location of bus 1, the location of bus 2 is older but new to the client.
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/location/events?bus_id=1,2", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", cursor(now.Add(time.Second), now.Add(-time.Second)))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	readEvent := func() []string {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return lines
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"id: " + cursor(now.Add(time.Second), now),
+		"event: location",
+		`data: {"bus_id":"2","long":0,"lat":2,"timestamp":"2023-01-01T00:00:00Z"}`,
+	}, readEvent())
+
+	// the bus doesn't move, its speed is derived from the previous location, the cursor keeps the timestamp of both buses.
+	tracker.Receive(track.Location{Lat: 1, Bus: track.Bus{ID: "1"}, Timestamp: now.Add(2 * time.Second)})
+	assert.Equal(t, []string{
+		"id: " + cursor(now.Add(2*time.Second), now),
+		"event: location",
+		`data: {"bus_id":"1","long":0,"lat":1,"timestamp":"2023-01-01T00:00:02Z","speed":0}`,
+	}, readEvent())
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/auth"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	frameAck   = "ack"
+	frameError = "error"
+)
+
+// streamRequest denotes a location pushed by the driver over the stream.
+// The seq of the location is echoed back in the ack, so unacknowledged locations can be resent after reconnecting.
+type streamRequest struct {
+	locationRequest
+}
+
+// StreamResponse denotes the reply of every location pushed by the driver.
+type StreamResponse struct {
+	Type   string             `json:"type"`
+	Seq    uint64             `json:"seq"`
+	Error  string             `json:"error,omitempty"`
+	Fields []track.FieldError `json:"fields,omitempty"`
+}
+
+// StreamLocation serve the /location/stream websocket, the driver keep one connection per trip
+// and push location continuously instead of a POST per location.
+// Every location is acked once it's accepted by the broker or buffered in the outbox,
+// a location that failed is answered with an error frame carrying the same seq.
+func (d *TrackingHandler) StreamLocation(w http.ResponseWriter, r *http.Request) {
+	busID := r.URL.Query().Get("bus_id")
+
+	driver, err := d.authenticateDriver(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+
+	c, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("error when upgrade header")
+		return
+	}
+	defer c.Close()
+	log.Debug().Str("bus_id", busID).Msg("driver stream opened")
+
+	// location is processed one by one, so the broker receive it in the order the driver sent it.
+	for {
+		_, msg, err := c.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				log.Debug().Str("bus_id", busID).Msg("driver stream closed by client")
+				return
+			}
+			log.Error().Err(err).Msg("websocket connection error")
+			return
+		}
+
+		// a malformed frame doesn't break the connection, it just can't be acked by seq.
+		var req streamRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			if err := c.WriteJSON(StreamResponse{Type: frameError, Error: "invalid request body"}); err != nil {
+				log.Error().Err(err).Msg("websocket write json error")
+				return
+			}
+			continue
+		}
+
+		resp := StreamResponse{Type: frameAck, Seq: req.Seq}
+		if err := d.sendStreamLocation(r, req.locationRequest, busID, driver); err != nil {
+			resp = StreamResponse{Type: frameError, Seq: req.Seq, Error: err.Error()}
+			var ve *track.ValidationError
+			if errors.As(err, &ve) {
+				resp.Fields = ve.Fields
+			}
+		}
+
+		if err := c.WriteJSON(resp); err != nil {
+			log.Error().Err(err).Msg("websocket write json error")
+			return
+		}
+	}
+}
+
+func (d *TrackingHandler) sendStreamLocation(r *http.Request, req locationRequest, busID string, driver *auth.Driver) error {
+	loc, err := d.location(req, busID)
+	if err != nil {
+		return err
+	}
+	if err := authorizeLocation(driver, &loc); err != nil {
+		return err
+	}
+
+	// the location will be delivered once the broker recover.
+	if err := d.trackingSvc.Send(r.Context(), loc); err != nil && !errors.Is(err, track.ErrBuffered) {
+		log.Error().Err(err).Msg("failed to send location")
+		return errors.New("internal server error")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamLocation(t *testing.T) {
+	svc := &fakeTrackingService{}
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(svc).StreamLocation))
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/location/stream?bus_id=1"
+	c, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	frames := []string{
+		`{"seq": 1, "long": 1, "lat": 1}`,
+		`{"seq": 2, "long": 2, "lat": 2, "timestamp": "yesterday"}`,
+		`not json`,
+		`{"seq": 3, "long": 3, "lat": 3, "bus_id": "2"}`,
+	}
+	want := []StreamResponse{
+		{Type: frameAck, Seq: 1},
+		{Type: frameError, Seq: 2, Error: "invalid location: timestamp: must be in RFC3339 format", Fields: []track.FieldError{{Field: "timestamp", Message: "must be in RFC3339 format"}}},
+		{Type: frameError, Error: "invalid request body"},
+		{Type: frameAck, Seq: 3},
+	}
+
+	for i, f := range frames {
+		require.NoError(t, c.WriteMessage(websocket.TextMessage, []byte(f)))
+
+		var resp StreamResponse
+		require.NoError(t, c.ReadJSON(&resp))
+		assert.Equal(t, want[i], resp)
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	require.Len(t, svc.sent, 2)
+	assert.Equal(t, "1", svc.sent[0].Bus.ID)
+	assert.Equal(t, "2", svc.sent[1].Bus.ID)
+	// the seq is kept with the location, so the tracking-service can drop the location sent again.
+	assert.Equal(t, uint64(1), svc.sent[0].Seq)
+	assert.Equal(t, uint64(3), svc.sent[1].Seq)
+}
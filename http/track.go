@@ -3,10 +3,15 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/auth"
 	"github.com/rafimuhammad01/tracking-app/track"
 	"github.com/rs/zerolog/log"
 )
@@ -16,19 +21,141 @@ var upgrader = websocket.Upgrader{} // use default options
 type Response struct {
 	Data  interface{} `json:"data,omitempty"`
 	Error string      `json:"error,omitempty"`
+	// Errors describe the invalid field of the request.
+	Errors []track.FieldError `json:"errors,omitempty"`
+}
+
+// LocationResponse denotes the location sent to the client.
+type LocationResponse struct {
+	BusID      string         `json:"bus_id"`
+	Long       float64        `json:"long"`
+	Lat        float64        `json:"lat"`
+	Timestamp  string         `json:"timestamp"`
+	Accuracy   *float64       `json:"accuracy,omitempty"`
+	Altitude   *float64       `json:"altitude,omitempty"`
+	Speed      *float64       `json:"speed,omitempty"`
+	Bearing    *float64       `json:"bearing,omitempty"`
+	Satellites *int           `json:"satellites,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Seq        uint64         `json:"seq,omitempty"`
+	TripID     string         `json:"trip_id,omitempty"`
+	Route      *RouteResponse `json:"route,omitempty"`
+	NextStop   *StopResponse  `json:"next_stop,omitempty"`
+}
+
+// RouteResponse denotes the route the bus is serving.
+type RouteResponse struct {
+	ID        string `json:"id"`
+	ShortName string `json:"short_name,omitempty"`
+	LongName  string `json:"long_name,omitempty"`
+}
+
+// StopResponse denotes the next stop of the bus.
+type StopResponse struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name,omitempty"`
+	Lat  float64 `json:"lat"`
+	Long float64 `json:"long"`
+}
+
+func newLocationResponse(l track.Location) LocationResponse {
+	resp := LocationResponse{
+		BusID:      l.Bus.ID,
+		Long:       l.Long,
+		Lat:        l.Lat,
+		Timestamp:  l.Timestamp.Format(time.RFC3339Nano),
+		Accuracy:   l.Accuracy,
+		Altitude:   l.Altitude,
+		Speed:      l.Speed,
+		Bearing:    l.Bearing,
+		Satellites: l.Satellites,
+		Source:     string(l.Source),
+		Seq:        l.Seq,
+		TripID:     l.TripID,
+	}
+	if l.Route != nil {
+		resp.Route = &RouteResponse{ID: l.Route.ID, ShortName: l.Route.ShortName, LongName: l.Route.LongName}
+	}
+	if l.NextStop != nil {
+		resp.NextStop = &StopResponse{ID: l.NextStop.ID, Name: l.NextStop.Name, Lat: l.NextStop.Lat, Long: l.NextStop.Long}
+	}
+	return resp
 }
 
 type TrackingHandler struct {
-	trackingSvc TrackingService
+	trackingSvc  TrackingService
+	maxBatchSize int
+	ws           WebSocketConfig
+
+	drain            drainer
+	reconnectBackoff time.Duration
+
+	driverAuth    *auth.Verifier
+	riderAuth     *auth.Verifier
+	sessionCookie string
 }
 
 type TrackingService interface {
 	Send(ctx context.Context, l track.Location) error
-	Register(c track.Customer, l chan track.Location)
-	Unregister(c track.Customer)
+	SendBatch(ctx context.Context, locs []track.Location) error
+	Register(c track.Customer, s track.Subscription, u chan track.Update) (track.Handle, error)
+	Subscribe(h track.Handle, busIDs []string) error
+	Unsubscribe(h track.Handle, busIDs []string) error
+	Unregister(h track.Handle)
+	Dropped(c track.Customer) uint64
+	LastLocation(busID string) (track.Location, bool)
+	LastLocations() []track.Location
+	Authorize(c track.Customer, busIDs []string) error
+	SubscribeRoutes(h track.Handle, routeIDs []string) ([]string, error)
+	UnsubscribeRoutes(h track.Handle, routeIDs []string) ([]string, error)
+	Validate(l track.Location) error
+	StartTrip(ctx context.Context, t track.Trip) (track.Trip, error)
+	UpdateTrip(ctx context.Context, t track.Trip) (track.Trip, error)
 }
 
+// GetLatestLocation serve the rider websocket.
+// The buses in bus_id are followed right away, the rider can change them later with the control protocol,
+// see control.go for the supported message.
 func (s *TrackingHandler) GetLatestLocation(w http.ResponseWriter, r *http.Request) {
+	// parse the buses the client want to follow before upgrading,
+	// so we can still answer with a proper http error.
+	busIDs := parseBusIDs(r.URL.Query())
+
+	if !s.drain.acquire() {
+		writeDraining(w, s.reconnectAfter())
+		return
+	}
+	defer s.drain.release()
+
+	// setup customer
+	customer, err := s.authenticateRider(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+	updates := make(chan track.Update)
+
+	// register customer so we can track
+	handle, err := s.trackingSvc.Register(customer, track.Subscription{BusIDs: busIDs}, updates)
+	if err != nil {
+		if errors.Is(err, track.ErrTooManyConnections) {
+			writeJSON(w, http.StatusTooManyRequests, Response{Error: "too many connections"})
+			return
+		}
+		if errors.Is(err, track.ErrNotAllowed) {
+			writeJSON(w, http.StatusForbidden, Response{Error: "bus not allowed"})
+			return
+		}
+		log.Error().Err(err).Msg("error when register client")
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
+		return
+	}
+	log.Debug().Any("customer", customer).Any("bus_ids", busIDs).Msg("client registered")
+	defer func() {
+		log.Debug().Any("customer", customer).Uint64("dropped", s.trackingSvc.Dropped(customer)).Msg("client unregistered")
+		s.trackingSvc.Unregister(handle)
+	}()
+
 	c, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("error when upgrade header")
@@ -36,122 +163,381 @@ func (s *TrackingHandler) GetLatestLocation(w http.ResponseWriter, r *http.Reque
 	}
 	defer c.Close()
 
-	// listen to disconnect event from client
+	sess := newRiderSession(handle, busIDs)
+	defer sess.close()
+
+	// a client that doesn't answer the ping is unregistered once the read deadline is reached.
+	s.ws.keepAlive(c)
+	ping := time.NewTicker(s.ws.PingInterval)
+	defer ping.Stop()
+
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if s.ws.MaxIdle > 0 {
+		idleTimer = time.NewTimer(s.ws.MaxIdle)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+	// the timer could have fired while the connection was busy, its stale expiry is drained
+	// so it doesn't close a connection that is active again.
+	active := func() {
+		if idleTimer == nil {
+			return
+		}
+		if !idleTimer.Stop() {
+			select {
+			case <-idleTimer.C:
+			default:
+			}
+		}
+		idleTimer.Reset(s.ws.MaxIdle)
+	}
+
+	// read the control message from the client.
 	// ReadMessage will be return error if client is disconnect
 	// WriteMessage won't
-	errChan := make(chan error)
+	errChan := make(chan error, 1)
+	ctrlChan := make(chan []byte)
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
 		for {
-			_, _, err := c.ReadMessage()
+			_, msg, err := c.ReadMessage()
 			if err != nil {
 				errChan <- err
+				return
+			}
+
+			select {
+			case ctrlChan <- msg:
+			case <-done:
+				return
 			}
 		}
 	}()
 
-	// setup customer
-	locChan := make(chan track.Location)
-	id := r.Header.Get("Session-ID")
-	customer := track.Customer{ID: id}
-
-	// register customer so we can track
-	s.trackingSvc.Register(customer, locChan)
-	log.Debug().Any("customer", customer).Msg("client registered")
-
 	// listen to location channel
 	for {
 		select {
 		case err := <-errChan:
-			s.trackingSvc.Unregister(customer)
 			if websocket.IsCloseError(err, websocket.CloseNoStatusReceived) {
 				log.Info().Msg("connection closed by client")
 				return
 			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				log.Info().Any("customer", customer).Msg("client missed heartbeat")
+				return
+			}
 			log.Error().Err(err).Msg("websocket connection error")
 			return
-		case l := <-locChan:
-			locResp := struct {
-				Long      float64 `json:"long"`
-				Lat       float64 `json:"lat"`
-				Timestamp string  `json:"timestamp"`
-			}{
-				Long:      l.Long,
-				Lat:       l.Lat,
-				Timestamp: l.Timestamp.Format(time.RFC3339Nano),
-			}
-			err = c.WriteJSON(locResp)
-			if err != nil {
+		case <-ping.C:
+			if err := s.ws.ping(c); err != nil {
+				log.Error().Err(err).Msg("websocket ping error")
+				return
+			}
+		case <-s.drain.shutdown:
+			log.Debug().Any("customer", customer).Msg("client closed on shutdown")
+			s.ws.close(c, websocket.CloseGoingAway, reconnectReason(s.reconnectAfter()))
+			s.ws.awaitClose(errChan)
+			return
+		case <-idle:
+			log.Info().Any("customer", customer).Msg("client closed after being idle")
+			s.ws.close(c, websocket.CloseNormalClosure, "idle timeout")
+			s.ws.awaitClose(errChan)
+			return
+		case msg := <-ctrlChan:
+			active()
+			if err := s.ws.writeJSON(c, s.control(sess, msg)); err != nil {
 				log.Error().Err(err).Msg("websocket write json error")
 				return
 			}
+
+			// the rate limit could be lifted, send what is still pending.
+			if sess.interval == 0 {
+				if err := s.writeLocations(c, sess.flush()...); err != nil {
+					return
+				}
+			}
+		case <-sess.tick():
+			locs := sess.flush()
+			if len(locs) > 0 {
+				active()
+			}
+			if err := s.writeLocations(c, locs...); err != nil {
+				return
+			}
+		case u, ok := <-updates:
+			// the hub close the channel when it evict a client that can't keep up.
+			if !ok {
+				log.Info().Any("customer", customer).Msg("client evicted as slow consumer")
+				s.ws.close(c, websocket.ClosePolicyViolation, "slow consumer")
+				s.ws.awaitClose(errChan)
+				return
+			}
+
+			if !sess.follows(u) {
+				continue
+			}
+
+			// trip event is never rate limited, and the pending location of an ended trip is stale.
+			if u.Trip != nil {
+				if u.Trip.Status == track.TripEnded {
+					delete(sess.pending, u.Trip.Bus.ID)
+				}
+				active()
+				if err := s.ws.writeJSON(c, TripFrame{Type: frameTrip, TripResponse: newTripResponse(*u.Trip)}); err != nil {
+					log.Error().Err(err).Msg("websocket write json error")
+					return
+				}
+				continue
+			}
+
+			l := *u.Location
+			if sess.interval > 0 {
+				sess.pending[l.Bus.ID] = l
+				continue
+			}
+
+			active()
+			if err := s.writeLocations(c, l); err != nil {
+				return
+			}
 		}
 	}
 }
 
+func (s *TrackingHandler) writeLocations(c *websocket.Conn, locs ...track.Location) error {
+	for _, l := range locs {
+		if err := s.ws.writeJSON(c, LocationFrame{Type: frameLocation, LocationResponse: newLocationResponse(l)}); err != nil {
+			log.Error().Err(err).Msg("websocket write json error")
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBusLocation serve GET /buses/{id}/location from the latest known location of the bus.
+func (s *TrackingHandler) GetBusLocation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	busID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/buses/"), "/location")
+	if !ok || busID == "" || strings.Contains(busID, "/") {
+		writeJSON(w, http.StatusNotFound, Response{Error: "not found"})
+		return
+	}
+
+	customer, err := s.authenticateRider(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+	if err := s.trackingSvc.Authorize(customer, []string{busID}); err != nil {
+		writeJSON(w, http.StatusForbidden, Response{Error: "bus not allowed"})
+		return
+	}
+
+	l, ok := s.trackingSvc.LastLocation(busID)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Response{Error: "location not found"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Data: newLocationResponse(l)})
+}
+
 func (d *TrackingHandler) SendLocation(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(Response{Error: "method not allowed"})
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	driver, err := d.authenticateDriver(r)
+	if err != nil {
+		writeUnauthorized(w, err)
 		return
 	}
 
 	// parse location data.
-	var loc track.Location
-	var locReq struct {
-		Long      float64 `json:"long"`
-		Lat       float64 `json:"lat"`
-		Timestamp string  `json:"timestamp"`
+	var locReq locationRequest
+	err = json.NewDecoder(r.Body).Decode(&locReq)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid request body"})
+		return
 	}
-	err := json.NewDecoder(r.Body).Decode(&locReq)
+
+	loc, err := d.location(locReq, r.URL.Query().Get("bus_id"))
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(Response{Error: "invalid request body"})
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := authorizeLocation(driver, &loc); err != nil {
+		writeJSON(w, http.StatusForbidden, Response{Error: err.Error()})
 		return
 	}
-	loc.Long = locReq.Long
-	loc.Lat = locReq.Lat
 
-	// parse vehicle data
-	busID := r.URL.Query().Get("bus_id")
-	if busID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(Response{Error: "invalid bus_id value"})
+	// send location
+	if err := d.trackingSvc.Send(r.Context(), loc); err != nil {
+		// the location will be delivered once the broker recover.
+		if errors.Is(err, track.ErrBuffered) {
+			writeJSON(w, http.StatusAccepted, Response{Data: "accepted"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
 		return
 	}
+
+	// return
+	writeJSON(w, http.StatusOK, Response{Data: "success"})
+}
+
+// locationRequest denotes a location sent by the driver.
+type locationRequest struct {
+	BusID      string   `json:"bus_id,omitempty"`
+	Long       float64  `json:"long"`
+	Lat        float64  `json:"lat"`
+	Timestamp  string   `json:"timestamp"`
+	Accuracy   *float64 `json:"accuracy,omitempty"`
+	Altitude   *float64 `json:"altitude,omitempty"`
+	Speed      *float64 `json:"speed,omitempty"`
+	Bearing    *float64 `json:"bearing,omitempty"`
+	Satellites *int     `json:"satellites,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Seq        uint64   `json:"seq,omitempty"`
+}
+
+// location convert the request to track.Location and validate it.
+// busID is used when the request doesn't carry its own bus_id.
+// A *track.ValidationError is returned when one or more field is invalid.
+func (d *TrackingHandler) location(req locationRequest, busID string) (track.Location, error) {
+	var loc track.Location
+	loc.Long = req.Long
+	loc.Lat = req.Lat
+	loc.Accuracy = req.Accuracy
+	loc.Altitude = req.Altitude
+	loc.Speed = req.Speed
+	loc.Bearing = req.Bearing
+	loc.Satellites = req.Satellites
+	loc.Source = track.Source(req.Source)
+	loc.Seq = req.Seq
+
+	// parse vehicle data
+	if req.BusID != "" {
+		busID = req.BusID
+	}
 	loc.Bus.ID = busID
 
 	// parse timestamp
+	var verr track.ValidationError
 	loc.Timestamp = time.Now()
-	if locReq.Timestamp != "" {
-		ts, err := time.Parse(time.RFC3339Nano, locReq.Timestamp)
+	if req.Timestamp != "" {
+		ts, err := time.Parse(time.RFC3339Nano, req.Timestamp)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(Response{Error: "invalid timestamp value"})
-			return
+			verr.Add("timestamp", "must be in RFC3339 format")
+		} else {
+			loc.Timestamp = ts
 		}
+	}
 
-		loc.Timestamp = ts
+	// report every invalid field at once.
+	if err := d.trackingSvc.Validate(loc); err != nil {
+		var ve *track.ValidationError
+		if !errors.As(err, &ve) {
+			return loc, err
+		}
+		verr.Fields = append(verr.Fields, ve.Fields...)
 	}
 
-	// send location
-	if err := d.trackingSvc.Send(r.Context(), loc); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(Response{Error: "internal server error"})
-		return
+	return loc, verr.Err()
+}
+
+// parseBusIDs reads the bus_id query parameter.
+// It accepts both repeated parameter (bus_id=1&bus_id=2) and comma separated value (bus_id=1,2).
+func parseBusIDs(q url.Values) []string {
+	var ids []string
+	for _, v := range q["bus_id"] {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
 	}
+	return ids
+}
 
-	// return
-	json.NewEncoder(w).Encode(Response{Data: "success"})
-	return
+// errorResponse returns the response of err, with the invalid field when err is a validation error.
+func errorResponse(err error) Response {
+	var ve *track.ValidationError
+	if errors.As(err, &ve) {
+		return Response{Error: "invalid location", Errors: ve.Fields}
+	}
+	return Response{Error: err.Error()}
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func NewHandler(trackingSvc TrackingService, opts ...opts) *TrackingHandler {
+	h := &TrackingHandler{
+		trackingSvc:      trackingSvc,
+		maxBatchSize:     defaultMaxBatchSize,
+		drain:            newDrainer(),
+		reconnectBackoff: defaultReconnectBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.ws = h.ws.withDefaults()
+
+	return h
+}
+
+type opts func(*TrackingHandler)
+
+// WithWebSocket configure the heartbeat and timeout of the rider websocket.
+func WithWebSocket(cfg WebSocketConfig) opts {
+	return func(h *TrackingHandler) {
+		h.ws = cfg
+	}
 }
 
-func NewHandler(trackingSvc TrackingService) *TrackingHandler {
-	return &TrackingHandler{
-		trackingSvc: trackingSvc,
+// WithReconnectBackoff set the backoff suggested to the client closed on shutdown.
+func WithReconnectBackoff(d time.Duration) opts {
+	return func(h *TrackingHandler) {
+		if d > 0 {
+			h.reconnectBackoff = d
+		}
+	}
+}
+
+// WithDriverAuth require the driver endpoints to be called with a token verified by v.
+func WithDriverAuth(v *auth.Verifier) opts {
+	return func(h *TrackingHandler) {
+		h.driverAuth = v
+	}
+}
+
+// WithRiderAuth require the rider endpoints to be called with a token verified by v,
+// sent as bearer token or as the value of the session cookie.
+func WithRiderAuth(v *auth.Verifier, sessionCookie string) opts {
+	return func(h *TrackingHandler) {
+		h.riderAuth = v
+		h.sessionCookie = sessionCookie
+	}
+}
+
+// WithMaxBatchSize limit the number of location accepted in a single batch request.
+func WithMaxBatchSize(n int) opts {
+	return func(h *TrackingHandler) {
+		if n > 0 {
+			h.maxBatchSize = n
+		}
 	}
 }
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+// TripResponse denotes a trip sent to the driver, and to the rider as a trip event.
+type TripResponse struct {
+	ID        string `json:"id"`
+	BusID     string `json:"bus_id"`
+	RouteID   string `json:"route_id"`
+	DriverID  string `json:"driver_id,omitempty"`
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+}
+
+func newTripResponse(t track.Trip) TripResponse {
+	return TripResponse{
+		ID:        t.ID,
+		BusID:     t.Bus.ID,
+		RouteID:   t.RouteID,
+		DriverID:  t.Driver.ID,
+		Status:    string(t.Status),
+		Timestamp: t.Timestamp.Format(time.RFC3339Nano),
+	}
+}
+
+// tripRequest denotes the trip started or updated by the driver.
+// The id is optional on start, it is generated when missing.
+type tripRequest struct {
+	ID      string `json:"id"`
+	BusID   string `json:"bus_id"`
+	RouteID string `json:"route_id"`
+}
+
+// tripActions maps the last segment of /trips/{id}/{action} to the trip status.
+var tripActions = map[string]track.TripStatus{
+	"pause":  track.TripPaused,
+	"resume": track.TripResumed,
+	"end":    track.TripEnded,
+}
+
+// StartTrip serve POST /trips, it starts a trip of the bus on the route.
+// The location sent by the bus is stamped with the trip by tracking-service until it is ended.
+func (d *TrackingHandler) StartTrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	driver, err := d.authenticateDriver(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+
+	var req tripRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid request body"})
+		return
+	}
+
+	trip := track.Trip{ID: req.ID, Bus: track.Bus{ID: req.BusID}, RouteID: req.RouteID}
+	if driver != nil {
+		if err := driver.AuthorizeTrip(&trip); err != nil {
+			writeJSON(w, http.StatusForbidden, Response{Error: err.Error()})
+			return
+		}
+	}
+
+	trip, err = d.trackingSvc.StartTrip(r.Context(), trip)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, Response{Data: newTripResponse(trip)})
+}
+
+// UpdateTrip serve POST /trips/{id}/pause, /trips/{id}/resume and /trips/{id}/end, the body carries the bus_id of the trip.
+// The event is accepted as is, tracking-service only applies it when the trip of the bus allows it,
+// so any driver-service instance can update a trip started on another one.
+func (d *TrackingHandler) UpdateTrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+		return
+	}
+
+	id, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/trips/"), "/")
+	status, known := tripActions[action]
+	if !ok || !known || id == "" {
+		writeJSON(w, http.StatusNotFound, Response{Error: "not found"})
+		return
+	}
+
+	driver, err := d.authenticateDriver(r)
+	if err != nil {
+		writeUnauthorized(w, err)
+		return
+	}
+
+	var req tripRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid request body"})
+		return
+	}
+
+	trip := track.Trip{ID: id, Bus: track.Bus{ID: req.BusID}, Status: status}
+	if driver != nil {
+		if err := driver.AuthorizeTrip(&trip); err != nil {
+			writeJSON(w, http.StatusForbidden, Response{Error: err.Error()})
+			return
+		}
+	}
+
+	trip, err = d.trackingSvc.UpdateTrip(r.Context(), trip)
+	if err != nil {
+		writeTripError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Data: newTripResponse(trip)})
+}
+
+func writeTripError(w http.ResponseWriter, err error) {
+	var ve *track.ValidationError
+	switch {
+	case errors.As(err, &ve):
+		writeJSON(w, http.StatusBadRequest, Response{Error: "invalid trip", Errors: ve.Fields})
+	default:
+		log.Error().Err(err).Msg("failed to send trip")
+		writeJSON(w, http.StatusInternalServerError, Response{Error: "internal server error"})
+	}
+}
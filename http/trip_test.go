@@ -0,0 +1,96 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tripSender struct {
+	trips []track.Trip
+}
+
+func (s *tripSender) Send(_ context.Context, _ track.Location) error {
+	return nil
+}
+
+func (s *tripSender) SendTrip(_ context.Context, t track.Trip) error {
+	s.trips = append(s.trips, t)
+	return nil
+}
+
+func TestTripEndpoints(t *testing.T) {
+	sender := &tripSender{}
+	h := NewHandler(track.NewTracker(track.WithSender(sender)))
+	mux := http.NewServeMux()
+	RegisterDriverRoutes(mux, h)
+
+	do := func(path, body string) (int, TripResponse) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+
+		var resp struct {
+			Data TripResponse `json:"data"`
+		}
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp.Data
+	}
+
+	code, _ := do("/trips", `{"bus_id":"1"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, trip := do("/trips", `{"bus_id":"1","route_id":"r1"}`)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "started", trip.Status)
+	assert.Equal(t, "r1", trip.RouteID)
+
+	code, _ = do("/trips", `{"id":"t1","bus_id":"1","route_id":"r1"}`)
+	assert.Equal(t, http.StatusCreated, code)
+
+	// the trip is updated with its bus, whichever instance started it.
+	code, _ = do("/trips/"+trip.ID+"/pause", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, paused := do("/trips/"+trip.ID+"/pause", `{"bus_id":"1"}`)
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "paused", paused.Status)
+
+	code, ended := do("/trips/t1/end", `{"bus_id":"1"}`)
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, "ended", ended.Status)
+
+	code, _ = do("/trips/"+trip.ID+"/unknown", `{"bus_id":"1"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	require.Len(t, sender.trips, 4)
+	assert.Equal(t, "t1", sender.trips[1].ID)
+	assert.Equal(t, track.Trip{ID: "t1", Bus: track.Bus{ID: "1"}, Status: track.TripEnded, Timestamp: sender.trips[3].Timestamp}, sender.trips[3])
+}
+
+func TestGetLocationEventsTrip(t *testing.T) {
+	tracker := track.NewTracker(track.WithHub(track.HubConfig{}), track.WithCache(0))
+	tracker.ReceiveTrip(track.Trip{ID: "t1", Bus: track.Bus{ID: "1"}, RouteID: "r1", Status: track.TripStarted})
+
+	srv := httptest.NewServer(http.HandlerFunc(NewHandler(tracker).GetLocationEvents))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/location/events?bus_id=1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// the trip in progress is sent right away, without id.
+	r := bufio.NewReader(resp.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: trip\n", line)
+	line, err = r.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"id":"t1","bus_id":"1","route_id":"r1","status":"started"`)
+}
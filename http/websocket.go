@@ -0,0 +1,70 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultPongTimeout  = 10 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+// WebSocketConfig denotes how the rider websocket detect dead connection.
+type WebSocketConfig struct {
+	// PingInterval is how often a ping is sent to the client.
+	PingInterval time.Duration
+	// PongTimeout is how long after the ping interval the client has to answer,
+	// a client that doesn't is considered gone and unregistered.
+	PongTimeout time.Duration
+	// WriteTimeout is the deadline of every write to the client.
+	WriteTimeout time.Duration
+	// MaxIdle close the connection when nothing but heartbeat is exchanged for that long.
+	// Zero means the connection is never closed for being idle.
+	MaxIdle time.Duration
+}
+
+func (cfg WebSocketConfig) withDefaults() WebSocketConfig {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.PongTimeout <= 0 {
+		cfg.PongTimeout = defaultPongTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = defaultWriteTimeout
+	}
+	return cfg
+}
+
+// keepAlive extend the read deadline of c every time the client answer a ping.
+func (cfg WebSocketConfig) keepAlive(c *websocket.Conn) {
+	c.SetReadDeadline(time.Now().Add(cfg.PingInterval + cfg.PongTimeout))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(cfg.PingInterval + cfg.PongTimeout))
+	})
+}
+
+func (cfg WebSocketConfig) ping(c *websocket.Conn) error {
+	return c.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.WriteTimeout))
+}
+
+func (cfg WebSocketConfig) writeJSON(c *websocket.Conn, v interface{}) error {
+	c.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+	return c.WriteJSON(v)
+}
+
+func (cfg WebSocketConfig) close(c *websocket.Conn, code int, text string) error {
+	return c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(cfg.WriteTimeout))
+}
+
+// awaitClose wait for the client to answer the close message,
+// so the connection isn't closed while the client is still writing to it.
+func (cfg WebSocketConfig) awaitClose(errChan <-chan error) {
+	select {
+	case <-errChan:
+	case <-time.After(cfg.WriteTimeout):
+	}
+}
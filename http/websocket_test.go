@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialRider(t *testing.T, h *TrackingHandler) *websocket.Conn {
+	srv := httptest.NewServer(http.HandlerFunc(h.GetLatestLocation))
+	t.Cleanup(srv.Close)
+
+	c, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/location?bus_id=1", nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestWebSocketMissedHeartbeat(t *testing.T) {
+	svc := &fakeTrackingService{unregistered: make(chan track.Handle, 1)}
+	dialRider(t, NewHandler(svc, WithWebSocket(WebSocketConfig{
+		PingInterval: 20 * time.Millisecond,
+		PongTimeout:  20 * time.Millisecond,
+	})))
+
+	// the client never read, so it never answer the ping.
+	select {
+	case <-svc.unregistered:
+	case <-time.After(time.Second):
+		t.Fatal("client that missed heartbeat is not unregistered")
+	}
+}
+
+func TestWebSocketMaxIdle(t *testing.T) {
+	svc := &fakeTrackingService{unregistered: make(chan track.Handle, 1)}
+	c := dialRider(t, NewHandler(svc, WithWebSocket(WebSocketConfig{
+		PingInterval: 10 * time.Millisecond,
+		MaxIdle:      100 * time.Millisecond,
+	})))
+
+	// answering the ping doesn't keep the connection from being idle.
+	_, _, err := c.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure), err)
+
+	select {
+	case <-svc.unregistered:
+	case <-time.After(time.Second):
+		t.Fatal("idle client is not unregistered")
+	}
+}
@@ -2,29 +2,71 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"expvar"
 	"io"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/codec"
 	"github.com/rafimuhammad01/tracking-app/track"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 )
 
-type Tracker struct {
-	receiver Receiver
+// Dead letter message headers, the original key and value are kept as is.
+const (
+	HeaderDeadLetterReason    = "dlq-reason"
+	HeaderDeadLetterTopic     = "dlq-source-topic"
+	HeaderDeadLetterPartition = "dlq-source-partition"
+	HeaderDeadLetterOffset    = "dlq-source-offset"
+)
+
+const (
+	deadLetterTimeout = 10 * time.Second
+	// a failed dead letter write is retried after deadLetterBackoff, doubled up to deadLetterMaxBackoff.
+	deadLetterBackoff    = time.Second
+	deadLetterMaxBackoff = 30 * time.Second
+)
+
+// stats exposes the kafka counters through expvar (/debug/vars).
+var stats = expvar.NewMap("kafka")
+
+// reader is the part of *kafka.Reader used by the tracker.
+type reader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
 
-	r *kafka.Reader
-	w *kafka.Writer
+// writer is the part of *kafka.Writer used by the tracker.
+type writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
 }
 
-type Receiver interface {
-	Receive(l track.Location)
+// Tracker publish and consume location through kafka.
+type Tracker struct {
+	receiver broker.Receiver
+	codec    codec.Codec
+
+	r       reader
+	w       writer
+	dlq     writer
+	backoff time.Duration
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
+// Listen pass every consumed message to the receiver, the offset of a message is committed
+// once it is received or forwarded to the dead letter topic.
+// The forward is retried until it succeeds, so a poison message is never committed without being kept.
 func (t *Tracker) Listen(ctx context.Context) {
 	for {
-		m, err := t.r.ReadMessage(ctx)
+		m, err := t.r.FetchMessage(ctx)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				log.Info().Msg("connection closed")
@@ -34,27 +76,110 @@ func (t *Tracker) Listen(ctx context.Context) {
 			continue
 		}
 
-		var loc track.Location
-		err = json.Unmarshal(m.Value, &loc)
+		if err := t.receive(m); err != nil {
+			// poison message must not reach the rider, park it for inspection instead.
+			stats.Add("decode_failures", 1)
+			log.Error().Err(err).Int("partition", m.Partition).Int64("offset", m.Offset).Msg("failed to unmarshal message")
+			if !t.forward(ctx, m, err) {
+				return
+			}
+		}
+
+		if err := t.r.CommitMessages(ctx, m); err != nil {
+			stats.Add("commit_failures", 1)
+			log.Error().Err(err).Int("partition", m.Partition).Int64("offset", m.Offset).Msg("failed to commit message")
+		}
+	}
+}
+
+// receive decode the message with the codec of its content type header and pass it to the receiver.
+func (t *Tracker) receive(m kafka.Message) error {
+	var contentType string
+	for _, h := range m.Headers {
+		if h.Key == codec.HeaderContentType {
+			contentType = string(h.Value)
+		}
+	}
+
+	if contentType == codec.ContentTypeTrip {
+		trip, err := codec.DecodeTrip(m.Value)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to unmarshal message")
+			return err
 		}
+		t.receiver.ReceiveTrip(trip)
+		return nil
+	}
 
-		t.receiver.Receive(loc)
+	loc, err := codec.Decode(contentType, m.Value)
+	if err != nil {
+		return err
 	}
+	t.receiver.Receive(loc)
+	return nil
+}
+
+// forward the message to the dead letter topic, retrying with backoff until it succeeds.
+// It returns false when the tracker is closed before, the message must not be committed then.
+func (t *Tracker) forward(ctx context.Context, m kafka.Message, reason error) bool {
+	backoff := t.backoff
+	for {
+		err := t.deadLetter(ctx, m, reason)
+		if err == nil {
+			return true
+		}
+		stats.Add("dead_letter_failures", 1)
+		log.Error().Err(err).Int("partition", m.Partition).Int64("offset", m.Offset).Dur("retry_in", backoff).Msg("failed to write message to dead letter topic")
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-t.done:
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, deadLetterMaxBackoff)
+	}
+}
+
+// deadLetter forward the original message to the dead letter topic
+// with the reason and its source position as headers.
+// The message is only skipped when no dead letter topic is configured.
+func (t *Tracker) deadLetter(ctx context.Context, m kafka.Message, reason error) error {
+	if t.dlq == nil {
+		return nil
+	}
+
+	headers := append([]kafka.Header{}, m.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderDeadLetterReason, Value: []byte(reason.Error())},
+		kafka.Header{Key: HeaderDeadLetterTopic, Value: []byte(m.Topic)},
+		kafka.Header{Key: HeaderDeadLetterPartition, Value: []byte(strconv.Itoa(m.Partition))},
+		kafka.Header{Key: HeaderDeadLetterOffset, Value: []byte(strconv.FormatInt(m.Offset, 10))},
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, deadLetterTimeout)
+	defer cancel()
+
+	err := t.dlq.WriteMessages(ctx, kafka.Message{
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	stats.Add("dead_letter_messages", 1)
+	return nil
 }
 
 func (t *Tracker) Send(ctx context.Context, l track.Location) error {
-	b, err := json.Marshal(l)
+	m, err := t.message(l)
 	if err != nil {
-		log.Debug().Err(err).Msg("failed to marshal location")
 		return err
 	}
 
-	err = t.w.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(l.Bus.ID),
-		Value: b,
-	})
+	err = t.w.WriteMessages(ctx, m)
 	if err != nil {
 		log.Debug().Err(err).Msg("failed to marshal location")
 		return err
@@ -63,28 +188,96 @@ func (t *Tracker) Send(ctx context.Context, l track.Location) error {
 	return nil
 }
 
-func (t *Tracker) ReaderCloser() error {
-	if err := t.r.Close(); err != nil {
+// SendBatch write every location in a single call.
+// Location is keyed by bus id, so location of the same bus keep its order in the partition.
+func (t *Tracker) SendBatch(ctx context.Context, locs []track.Location) error {
+	msgs := make([]kafka.Message, 0, len(locs))
+	for _, l := range locs {
+		m, err := t.message(l)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, m)
+	}
+
+	if err := t.w.WriteMessages(ctx, msgs...); err != nil {
+		log.Debug().Err(err).Int("size", len(msgs)).Msg("failed to write location batch")
 		return err
 	}
+
 	return nil
 }
 
-func (t *Tracker) WriterCloser() error {
-	if err := t.w.Close(); err != nil {
+// SendTrip write the trip event keyed by bus id, so it keeps its order with the location of the bus.
+func (t *Tracker) SendTrip(ctx context.Context, trip track.Trip) error {
+	b, err := codec.EncodeTrip(trip)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal trip")
 		return err
 	}
+
+	err = t.w.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(trip.Bus.ID),
+		Value: b,
+		Headers: []kafka.Header{
+			{Key: codec.HeaderContentType, Value: []byte(codec.ContentTypeTrip)},
+		},
+	})
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to write trip")
+		return err
+	}
+
 	return nil
 }
 
-func (t *Tracker) ReaderTopic() kafka.ReaderConfig {
-	return t.r.Config()
+func (t *Tracker) message(l track.Location) (kafka.Message, error) {
+	b, err := t.codec.Encode(l)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal location")
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Key:   []byte(l.Bus.ID),
+		Value: b,
+		Headers: []kafka.Header{
+			{Key: codec.HeaderContentType, Value: []byte(t.codec.ContentType())},
+		},
+	}, nil
+}
+
+// Close stop the dead letter retry, and close the reader, the writer and the dead letter writer that are configured.
+func (t *Tracker) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	if t.r != nil {
+		if err := t.r.Close(); err != nil {
+			return err
+		}
+	}
+	if t.w != nil {
+		if err := t.w.Close(); err != nil {
+			return err
+		}
+	}
+	if t.dlq != nil {
+		if err := t.dlq.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type opts func(*Tracker)
 
 func NewTracker(opts ...opts) *Tracker {
-	t := Tracker{}
+	t := Tracker{
+		codec:   codec.JSON{},
+		backoff: deadLetterBackoff,
+		done:    make(chan struct{}),
+	}
 
 	for _, opt := range opts {
 		opt(&t)
@@ -93,15 +286,37 @@ func NewTracker(opts ...opts) *Tracker {
 	return &t
 }
 
-func WithReceiver(rc Receiver, r *kafka.Reader) opts {
+func WithReceiver(rc broker.Receiver, r *kafka.Reader) opts {
 	return func(t *Tracker) {
 		t.receiver = rc
-		t.r = r
+		if r != nil {
+			t.r = r
+		}
 	}
 }
 
 func WithWriter(w *kafka.Writer) opts {
 	return func(t *Tracker) {
-		t.w = w
+		if w != nil {
+			t.w = w
+		}
+	}
+}
+
+// WithDeadLetter will forward message that can't be decoded to w instead of dropping them.
+// Nil writer only skip the message.
+func WithDeadLetter(w *kafka.Writer) opts {
+	return func(t *Tracker) {
+		if w != nil {
+			t.dlq = w
+		}
+	}
+}
+
+// WithCodec will encode the sent location with c, JSON is used by default.
+// Received message is always decoded by its content type header.
+func WithCodec(c codec.Codec) opts {
+	return func(t *Tracker) {
+		t.codec = c
 	}
 }
@@ -0,0 +1,157 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/codec"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKafka is the reader of the consumed messages and the dead letter writer,
+// events records the dead letter writes and the commits in order,
+// the first failures dead letter writes fail, every write fails when it is negative.
+type fakeKafka struct {
+	mu       sync.Mutex
+	messages []kafka.Message
+	dead     []kafka.Message
+	events   []string
+	failures int
+}
+
+func (f *fakeKafka) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.messages) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	m := f.messages[0]
+	f.messages = f.messages[1:]
+	return m, nil
+}
+
+func (f *fakeKafka) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, m := range msgs {
+		f.events = append(f.events, "commit "+strconv.FormatInt(m.Offset, 10))
+	}
+	return nil
+}
+
+func (f *fakeKafka) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures != 0 {
+		f.failures--
+		f.events = append(f.events, "dead letter failed")
+		return errors.New("dead letter unavailable")
+	}
+	for _, m := range msgs {
+		f.dead = append(f.dead, m)
+		f.events = append(f.events, "dead letter")
+	}
+	return nil
+}
+
+func (f *fakeKafka) Close() error {
+	return nil
+}
+
+type receiver struct {
+	locations []track.Location
+}
+
+func (r *receiver) Receive(l track.Location) {
+	r.locations = append(r.locations, l)
+}
+
+func (r *receiver) ReceiveTrip(t track.Trip) {}
+
+func TestListenDeadLetter(t *testing.T) {
+	valid, err := codec.JSON{}.Encode(track.Location{Lat: 1, Bus: track.Bus{ID: "1"}})
+	require.NoError(t, err)
+	contentType := kafka.Header{Key: codec.HeaderContentType, Value: []byte(codec.ContentTypeJSON)}
+
+	f := &fakeKafka{messages: []kafka.Message{
+		{Topic: "location", Partition: 2, Offset: 7, Key: []byte("1"), Value: []byte("{"), Headers: []kafka.Header{contentType}},
+		{Topic: "location", Partition: 2, Offset: 8, Key: []byte("1"), Value: valid, Headers: []kafka.Header{contentType}},
+	}}
+	rc := &receiver{}
+	tracker := NewTracker()
+	tracker.receiver, tracker.r, tracker.dlq = rc, f, f
+
+	tracker.Listen(context.Background())
+
+	require.Len(t, rc.locations, 1)
+	assert.Equal(t, float64(1), rc.locations[0].Lat)
+
+	// the poison message is kept as is, with its reason and source position.
+	require.Len(t, f.dead, 1)
+	assert.Equal(t, []byte("1"), f.dead[0].Key)
+	assert.Equal(t, []byte("{"), f.dead[0].Value)
+	headers := make(map[string]string)
+	for _, h := range f.dead[0].Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	assert.Equal(t, codec.ContentTypeJSON, headers[codec.HeaderContentType])
+	assert.NotEmpty(t, headers[HeaderDeadLetterReason])
+	assert.Equal(t, "location", headers[HeaderDeadLetterTopic])
+	assert.Equal(t, "2", headers[HeaderDeadLetterPartition])
+	assert.Equal(t, "7", headers[HeaderDeadLetterOffset])
+
+	// the offset of the poison message is committed once it is forwarded.
+	assert.Equal(t, []string{"dead letter", "commit 7", "commit 8"}, f.events)
+}
+
+func TestListenDeadLetterFailure(t *testing.T) {
+	contentType := kafka.Header{Key: codec.HeaderContentType, Value: []byte(codec.ContentTypeJSON)}
+	poison := func() []kafka.Message {
+		return []kafka.Message{{Topic: "location", Partition: 2, Offset: 7, Value: []byte("{"), Headers: []kafka.Header{contentType}}}
+	}
+
+	t.Run("retry until forwarded", func(t *testing.T) {
+		f := &fakeKafka{messages: poison(), failures: 2}
+		tracker := NewTracker()
+		tracker.receiver, tracker.r, tracker.dlq, tracker.backoff = &receiver{}, f, f, time.Millisecond
+
+		tracker.Listen(context.Background())
+
+		require.Len(t, f.dead, 1)
+		assert.Equal(t, []string{"dead letter failed", "dead letter failed", "dead letter", "commit 7"}, f.events)
+	})
+
+	t.Run("not committed when closed", func(t *testing.T) {
+		f := &fakeKafka{messages: poison(), failures: -1}
+		tracker := NewTracker()
+		tracker.receiver, tracker.r, tracker.dlq, tracker.backoff = &receiver{}, f, f, time.Millisecond
+
+		stopped := make(chan struct{})
+		go func() {
+			tracker.Listen(context.Background())
+			close(stopped)
+		}()
+
+		assert.Eventually(t, func() bool {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return len(f.events) >= 3
+		}, time.Second, time.Millisecond)
+		require.NoError(t, tracker.Close())
+		<-stopped
+
+		assert.Empty(t, f.dead)
+		assert.NotContains(t, f.events, "commit 7")
+	})
+}
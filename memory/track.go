@@ -0,0 +1,174 @@
+// Package memory is an in-process broker, used to run both services in a single binary and in tests.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/track"
+)
+
+const defaultBufferSize = 1024
+
+// ErrClosed returned when sending to a closed broker.
+var ErrClosed = errors.New("broker closed")
+
+// Broker deliver every sent location and trip event to all listening trackers in order.
+type Broker struct {
+	mu        sync.RWMutex
+	listeners map[chan message]chan struct{} // listener -> closed on unsubscribe
+	closed    bool
+}
+
+// message carries either a location or a trip event.
+type message struct {
+	location track.Location
+	trip     *track.Trip
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		listeners: make(map[chan message]chan struct{}),
+	}
+}
+
+// Send wait until every listener has room for the location, like a broker applying back pressure.
+func (b *Broker) Send(ctx context.Context, l track.Location) error {
+	return b.send(ctx, message{location: l})
+}
+
+// SendTrip wait until every listener has room for the trip event.
+func (b *Broker) SendTrip(ctx context.Context, t track.Trip) error {
+	return b.send(ctx, message{trip: &t})
+}
+
+// send copy the listeners and release the lock before waiting on them,
+// so a listener can unsubscribe while the send is blocked on its full channel.
+func (b *Broker) send(ctx context.Context, m message) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	listeners := make(map[chan message]chan struct{}, len(b.listeners))
+	for ch, done := range b.listeners {
+		listeners[ch] = done
+	}
+	b.mu.RUnlock()
+
+	for ch, done := range listeners {
+		select {
+		case ch <- m:
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *Broker) subscribe() chan message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan message, defaultBufferSize)
+	b.listeners[ch] = make(chan struct{})
+	return ch
+}
+
+func (b *Broker) unsubscribe(ch chan message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if done, ok := b.listeners[ch]; ok {
+		close(done)
+		delete(b.listeners, ch)
+	}
+}
+
+// Close the broker, sending location after that will fail.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	return nil
+}
+
+// Tracker publish and consume location through the in-process broker.
+type Tracker struct {
+	b        *Broker
+	receiver broker.Receiver
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *Tracker) Send(ctx context.Context, l track.Location) error {
+	return t.b.Send(ctx, l)
+}
+
+func (t *Tracker) SendTrip(ctx context.Context, trip track.Trip) error {
+	return t.b.SendTrip(ctx, trip)
+}
+
+func (t *Tracker) SendBatch(ctx context.Context, locs []track.Location) error {
+	for _, l := range locs {
+		if err := t.b.Send(ctx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen pass every location and trip event sent to the broker to the receiver until the tracker is closed.
+func (t *Tracker) Listen(ctx context.Context) {
+	ch := t.b.subscribe()
+	defer t.b.unsubscribe(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.done:
+			return
+		case m := <-ch:
+			if m.trip != nil {
+				t.receiver.ReceiveTrip(*m.trip)
+				continue
+			}
+			t.receiver.Receive(m.location)
+		}
+	}
+}
+
+// Close stop the listener. The broker itself is left open for other trackers.
+func (t *Tracker) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	return nil
+}
+
+type opts func(*Tracker)
+
+func NewTracker(b *Broker, opts ...opts) *Tracker {
+	t := Tracker{
+		b:    b,
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&t)
+	}
+
+	return &t
+}
+
+func WithReceiver(rc broker.Receiver) opts {
+	return func(t *Tracker) {
+		t.receiver = rc
+	}
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackerDeliverToListener(t *testing.T) {
+	b := NewBroker()
+
+	rider := track.NewTracker(track.WithHub(track.HubConfig{}))
+	subscriber := NewTracker(b, WithReceiver(rider))
+	driver := track.NewTracker(track.WithSender(NewTracker(b)))
+
+	go subscriber.Listen(context.Background())
+
+	l := make(chan track.Update)
+	hd, err := rider.Register(track.Customer{ID: "1"}, track.Subscription{BusIDs: []string{"1"}}, l)
+	assert.NoError(t, err)
+
+	// wait until the listener is subscribed to the broker.
+	assert.Eventually(t, func() bool {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+		return len(b.listeners) == 1
+	}, time.Second, time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		err := driver.Send(context.Background(), track.Location{Lat: float64(i), Bus: track.Bus{ID: "1"}})
+		assert.NoError(t, err)
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, float64(i), (<-l).Location.Lat)
+	}
+
+	// trip event is delivered in order with the location.
+	trip, err := driver.StartTrip(context.Background(), track.Trip{Bus: track.Bus{ID: "1"}, RouteID: "r1"})
+	assert.NoError(t, err)
+	assert.NoError(t, driver.Send(context.Background(), track.Location{Lat: 3, Bus: track.Bus{ID: "1"}}))
+	assert.Equal(t, trip, *(<-l).Trip)
+	assert.Equal(t, trip.ID, (<-l).Location.TripID)
+
+	rider.Unregister(hd)
+	assert.NoError(t, subscriber.Close())
+	assert.NoError(t, b.Close())
+	assert.ErrorIs(t, driver.Send(context.Background(), track.Location{}), ErrClosed)
+}
+
+func TestBrokerUnsubscribeDuringSend(t *testing.T) {
+	b := NewBroker()
+	ch := b.subscribe()
+	for i := 0; i < defaultBufferSize; i++ {
+		assert.NoError(t, b.Send(context.Background(), track.Location{}))
+	}
+
+	// the send is blocked on the full listener until it unsubscribes.
+	sent := make(chan error, 1)
+	go func() { sent <- b.Send(context.Background(), track.Location{}) }()
+	assert.Never(t, func() bool { return len(sent) > 0 }, 50*time.Millisecond, time.Millisecond)
+
+	unsubscribed := make(chan struct{})
+	go func() {
+		b.unsubscribe(ch)
+		close(unsubscribed)
+	}()
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe blocked by the send")
+	}
+	select {
+	case err := <-sent:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("send still blocked after unsubscribe")
+	}
+}
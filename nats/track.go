@@ -0,0 +1,162 @@
+// Package nats publish and consume location through NATS core subject.
+// Every tracking-service instance subscribe without queue group, so all of them receive every location.
+package nats
+
+import (
+	"context"
+	"expvar"
+
+	"github.com/nats-io/nats.go"
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/codec"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultBufferSize = 1024
+
+// stats exposes the nats counters through expvar (/debug/vars).
+var stats = expvar.NewMap("nats")
+
+// Tracker publish and consume location through nats.
+type Tracker struct {
+	conn     *nats.Conn
+	subject  string
+	codec    codec.Codec
+	receiver broker.Receiver
+
+	done chan struct{}
+}
+
+func (t *Tracker) Send(ctx context.Context, l track.Location) error {
+	b, err := t.codec.Encode(l)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal location")
+		return err
+	}
+
+	msg := nats.NewMsg(t.subject)
+	msg.Data = b
+	msg.Header.Set(codec.HeaderContentType, t.codec.ContentType())
+	if err := t.conn.PublishMsg(msg); err != nil {
+		log.Debug().Err(err).Msg("failed to publish location")
+		return err
+	}
+
+	return nil
+}
+
+// SendTrip publish the trip event on the same subject as the location.
+func (t *Tracker) SendTrip(ctx context.Context, trip track.Trip) error {
+	b, err := codec.EncodeTrip(trip)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal trip")
+		return err
+	}
+
+	msg := nats.NewMsg(t.subject)
+	msg.Data = b
+	msg.Header.Set(codec.HeaderContentType, codec.ContentTypeTrip)
+	if err := t.conn.PublishMsg(msg); err != nil {
+		log.Debug().Err(err).Msg("failed to publish trip")
+		return err
+	}
+
+	return nil
+}
+
+// SendBatch publish the locations in order, nats buffer them and flush them together.
+func (t *Tracker) SendBatch(ctx context.Context, locs []track.Location) error {
+	for _, l := range locs {
+		if err := t.Send(ctx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Listen pass every location published to the subject to the receiver until the tracker is closed.
+func (t *Tracker) Listen(ctx context.Context) {
+	ch := make(chan *nats.Msg, defaultBufferSize)
+	sub, err := t.conn.ChanSubscribe(t.subject, ch)
+	if err != nil {
+		log.Error().Err(err).Str("subject", t.subject).Msg("failed to subscribe")
+		return
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.done:
+			return
+		case m := <-ch:
+			if err := t.receive(m.Header.Get(codec.HeaderContentType), m.Data); err != nil {
+				stats.Add("decode_failures", 1)
+				log.Error().Err(err).Msg("failed to unmarshal message")
+			}
+		}
+	}
+}
+
+// receive decode the message with the codec of its content type and pass it to the receiver.
+func (t *Tracker) receive(contentType string, b []byte) error {
+	if contentType == codec.ContentTypeTrip {
+		trip, err := codec.DecodeTrip(b)
+		if err != nil {
+			return err
+		}
+		t.receiver.ReceiveTrip(trip)
+		return nil
+	}
+
+	loc, err := codec.Decode(contentType, b)
+	if err != nil {
+		return err
+	}
+	t.receiver.Receive(loc)
+	return nil
+}
+
+// Close stop the listener and drain the connection.
+func (t *Tracker) Close() error {
+	select {
+	case <-t.done:
+		return nil
+	default:
+		close(t.done)
+	}
+	return t.conn.Drain()
+}
+
+type opts func(*Tracker)
+
+func NewTracker(conn *nats.Conn, subject string, opts ...opts) *Tracker {
+	t := Tracker{
+		conn:    conn,
+		subject: subject,
+		codec:   codec.JSON{},
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&t)
+	}
+
+	return &t
+}
+
+func WithReceiver(rc broker.Receiver) opts {
+	return func(t *Tracker) {
+		t.receiver = rc
+	}
+}
+
+// WithCodec will encode the sent location with c, JSON is used by default.
+// Received message is always decoded by its content type header.
+func WithCodec(c codec.Codec) opts {
+	return func(t *Tracker) {
+		t.codec = c
+	}
+}
@@ -0,0 +1,592 @@
+// Package outbox keeps location that can't be published yet in an on-disk write ahead log,
+// and publish them in order once the broker recovers.
+package outbox
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"expvar"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/codec"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	logFile        = "outbox.log"
+	checkpointFile = "outbox.offset"
+
+	// every record is prefixed by its payload length and crc32 checksum.
+	headerSize = 8
+	// recordTrip prefix the payload of a trip event record,
+	// the payload of a location record is its JSON encoding, starting with '{'.
+	recordTrip byte = 't'
+
+	defaultMaxBytes      = 100 << 20
+	defaultRetryInterval = time.Second
+	defaultFsyncInterval = time.Second
+
+	// publishBatchSize is the maximum number of records published by Run at once.
+	publishBatchSize = 500
+)
+
+// ErrFull returned when the outbox reach its maximum size.
+var ErrFull = errors.New("outbox is full")
+
+// stats exposes the outbox counters through expvar (/debug/vars).
+var stats = expvar.NewMap("outbox")
+
+// FsyncPolicy decide when the outbox file is flushed to the disk.
+type FsyncPolicy string
+
+const (
+	// FsyncAlways flush after every write, nothing is lost on crash.
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval flush periodically, at most one interval of location is lost on crash.
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leave the flush to the operating system.
+	FsyncNever FsyncPolicy = "never"
+)
+
+// ParseFsyncPolicy parse the policy from its config value.
+// Empty value will be defaulted to FsyncInterval.
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch p := FsyncPolicy(s); p {
+	case "":
+		return FsyncInterval, nil
+	case FsyncAlways, FsyncInterval, FsyncNever:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown fsync policy %q", s)
+	}
+}
+
+// Config denotes the outbox size and durability.
+type Config struct {
+	// MaxBytes is the maximum size of the records waiting to be published.
+	// The published records are removed from the file once they take half of it.
+	MaxBytes int64
+	// Fsync decide when the outbox file is flushed to the disk.
+	Fsync FsyncPolicy
+	// FsyncInterval is the flush period of FsyncInterval policy.
+	FsyncInterval time.Duration
+	// RetryInterval is the wait time before retrying to publish after a failure.
+	RetryInterval time.Duration
+}
+
+// Outbox is a track.Sender that publish location right away while the broker is healthy.
+// Once publishing fail, the location and every following one is appended to the log
+// until Run drained it, so the order of location is kept.
+// Trip event goes through the same log, so it is never published before the location sent before it.
+//
+// The log is a sequence of records, the offset of the next record to publish is
+// kept in a separate checkpoint file. The log is truncated once everything is published,
+// and compacted when the published records take half of MaxBytes while some are still pending.
+type Outbox struct {
+	dir string
+
+	mu         sync.Mutex
+	log        *os.File
+	checkpoint *os.File
+	readOff    int64
+	writeOff   int64
+	dirty      bool
+
+	pub   track.Sender
+	codec codec.Codec
+	cfg   Config
+
+	notify  chan struct{}
+	running atomic.Bool
+	done    chan struct{}
+	closed  chan struct{}
+}
+
+// Open the outbox stored in dir, creating it if needed.
+// Location left by the previous run is published once Run is called.
+func Open(dir string, pub track.Sender, cfg Config) (*Outbox, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultMaxBytes
+	}
+	if cfg.Fsync == "" {
+		cfg.Fsync = FsyncInterval
+	}
+	if cfg.FsyncInterval <= 0 {
+		cfg.FsyncInterval = defaultFsyncInterval
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	lf, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	cf, err := os.OpenFile(filepath.Join(dir, checkpointFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		lf.Close()
+		return nil, err
+	}
+
+	o := &Outbox{
+		dir:        dir,
+		log:        lf,
+		checkpoint: cf,
+		pub:        pub,
+		codec:      codec.JSON{},
+		cfg:        cfg,
+		notify:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		closed:     make(chan struct{}),
+	}
+	if err := o.recover(); err != nil {
+		lf.Close()
+		cf.Close()
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// recover read the checkpoint and drop any torn record at the end of the log.
+func (o *Outbox) recover() error {
+	var buf [8]byte
+	if _, err := o.checkpoint.ReadAt(buf[:], 0); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	o.readOff = int64(binary.BigEndian.Uint64(buf[:]))
+
+	info, err := o.log.Stat()
+	if err != nil {
+		return err
+	}
+	// the log is only truncated once everything is published, a checkpoint beyond an empty log
+	// is left by a crash before the reset checkpoint reached the disk.
+	if info.Size() == 0 && o.readOff > 0 {
+		log.Warn().Int64("offset", o.readOff).Msg("resetting outbox checkpoint of an empty log")
+		o.readOff = 0
+		if err := o.writeCheckpoint(0); err != nil {
+			return err
+		}
+	}
+
+	off := o.readOff
+	for {
+		_, next, err := o.read(off)
+		if err != nil {
+			break
+		}
+		off = next
+	}
+
+	if off < o.readOff || off > info.Size() {
+		return fmt.Errorf("checkpoint offset %d is beyond the outbox log", o.readOff)
+	}
+	if off < info.Size() {
+		log.Warn().Int64("offset", off).Int64("size", info.Size()).Msg("truncating torn record in outbox")
+		if err := o.log.Truncate(off); err != nil {
+			return err
+		}
+	}
+	o.writeOff = off
+
+	return nil
+}
+
+// Send publish the location, or append it to the outbox when the broker is unavailable
+// or there is location pending before it. track.ErrBuffered is returned when the location is appended.
+func (o *Outbox) Send(ctx context.Context, l track.Location) error {
+	if o.Pending() == 0 {
+		err := o.pub.Send(ctx, l)
+		if err == nil {
+			return nil
+		}
+		log.Warn().Err(err).Msg("failed to publish location, buffering it in outbox")
+	}
+
+	if err := o.append(l); err != nil {
+		return err
+	}
+	return track.ErrBuffered
+}
+
+// SendBatch publish the locations in a single call, or append all of them to the outbox
+// when the broker is unavailable or there is location pending before them.
+// When the publisher partially fail, the already published location could be published twice.
+func (o *Outbox) SendBatch(ctx context.Context, locs []track.Location) error {
+	if o.Pending() == 0 {
+		err := track.SendBatch(ctx, o.pub, locs)
+		if err == nil || errors.Is(err, track.ErrBuffered) {
+			return err
+		}
+		log.Warn().Err(err).Int("size", len(locs)).Msg("failed to publish location batch, buffering it in outbox")
+	}
+
+	if err := o.append(locs...); err != nil {
+		return err
+	}
+	return track.ErrBuffered
+}
+
+// SendTrip publish the trip event, or append it to the outbox when the broker is unavailable
+// or there is location pending before it. track.ErrBuffered is returned when the trip event is appended.
+// The publisher must be a track.TripSender.
+func (o *Outbox) SendTrip(ctx context.Context, t track.Trip) error {
+	ts, ok := o.pub.(track.TripSender)
+	if !ok {
+		return errors.New("outbox publisher can't send trip event")
+	}
+
+	if o.Pending() == 0 {
+		err := ts.SendTrip(ctx, t)
+		if err == nil {
+			return nil
+		}
+		log.Warn().Err(err).Msg("failed to publish trip, buffering it in outbox")
+	}
+
+	payload, err := codec.EncodeTrip(t)
+	if err != nil {
+		return err
+	}
+	if err := o.write(append([]byte{recordTrip}, payload...)); err != nil {
+		return err
+	}
+	return track.ErrBuffered
+}
+
+// Pending returns the size in bytes of location waiting to be published.
+func (o *Outbox) Pending() int64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.writeOff - o.readOff
+}
+
+// append the locations as a whole, either all of them fit in the outbox or none is appended.
+func (o *Outbox) append(locs ...track.Location) error {
+	payloads := make([][]byte, 0, len(locs))
+	for _, l := range locs {
+		payload, err := o.codec.Encode(l)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+	return o.write(payloads...)
+}
+
+// write the payloads as records, either all of them fit in the outbox or none is written.
+func (o *Outbox) write(payloads ...[]byte) error {
+	var recs []byte
+	for _, payload := range payloads {
+		var header [headerSize]byte
+		binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+		binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+		recs = append(recs, header[:]...)
+		recs = append(recs, payload...)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.writeOff-o.readOff+int64(len(recs)) > o.cfg.MaxBytes {
+		stats.Add("rejected", int64(len(payloads)))
+		return ErrFull
+	}
+
+	if _, err := o.log.WriteAt(recs, o.writeOff); err != nil {
+		return err
+	}
+	if err := o.sync(o.log); err != nil {
+		return err
+	}
+	o.writeOff += int64(len(recs))
+	stats.Add("buffered", int64(len(payloads)))
+
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// record is a location or a trip event kept in the outbox.
+type record struct {
+	location track.Location
+	trip     *track.Trip
+}
+
+// read the record at off, returning the offset of the next record.
+func (o *Outbox) read(off int64) (record, int64, error) {
+	var header [headerSize]byte
+	if _, err := o.log.ReadAt(header[:], off); err != nil {
+		return record{}, 0, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := o.log.ReadAt(payload, off+headerSize); err != nil {
+		return record{}, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return record{}, 0, errors.New("outbox record checksum mismatch")
+	}
+	next := off + headerSize + int64(len(payload))
+
+	if len(payload) > 0 && payload[0] == recordTrip {
+		t, err := codec.DecodeTrip(payload[1:])
+		if err != nil {
+			return record{}, 0, err
+		}
+		return record{trip: &t}, next, nil
+	}
+
+	l, err := o.codec.Decode(payload)
+	if err != nil {
+		return record{}, 0, err
+	}
+	return record{location: l}, next, nil
+}
+
+// commit mark every record before next as published.
+// The log is truncated once everything is published, the checkpoint is reset and flushed before,
+// so a crash in between only publish the records of the log again.
+func (o *Outbox) commit(next int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.readOff = next
+	if o.readOff < o.writeOff {
+		if o.readOff >= o.cfg.MaxBytes/2 {
+			return o.compact()
+		}
+		if err := o.writeCheckpoint(o.readOff); err != nil {
+			return err
+		}
+		return o.sync(o.checkpoint)
+	}
+
+	if err := o.writeCheckpoint(0); err != nil {
+		return err
+	}
+	if o.cfg.Fsync != FsyncNever {
+		if err := o.checkpoint.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := o.log.Truncate(0); err != nil {
+		return err
+	}
+	o.readOff, o.writeOff = 0, 0
+	return nil
+}
+
+// compact replace the log by its pending records. The new log is written aside, then the checkpoint
+// is reset and flushed before the new log is renamed over the old one,
+// so a crash in between only publish the records of the old log again.
+// The caller must hold o.mu.
+func (o *Outbox) compact() error {
+	path := filepath.Join(o.dir, logFile)
+	f, err := os.OpenFile(path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	size, err := io.Copy(f, io.NewSectionReader(o.log, o.readOff, o.writeOff-o.readOff))
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := o.writeCheckpoint(0); err != nil {
+		f.Close()
+		return err
+	}
+	if o.cfg.Fsync != FsyncNever {
+		if err := o.checkpoint.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		f.Close()
+		return err
+	}
+
+	o.log.Close()
+	o.log = f
+	o.readOff, o.writeOff = 0, size
+	stats.Add("compactions", 1)
+	return nil
+}
+
+func (o *Outbox) writeCheckpoint(off int64) error {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(off))
+	_, err := o.checkpoint.WriteAt(buf[:], 0)
+	return err
+}
+
+// sync flush f when the policy is FsyncAlways, otherwise mark the outbox dirty for the next flush.
+// The caller must hold o.mu.
+func (o *Outbox) sync(f *os.File) error {
+	switch o.cfg.Fsync {
+	case FsyncAlways:
+		return f.Sync()
+	case FsyncInterval:
+		o.dirty = true
+	}
+	return nil
+}
+
+func (o *Outbox) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.dirty {
+		return
+	}
+	if err := o.log.Sync(); err != nil {
+		log.Error().Err(err).Msg("failed to sync outbox log")
+	}
+	if err := o.checkpoint.Sync(); err != nil {
+		log.Error().Err(err).Msg("failed to sync outbox checkpoint")
+	}
+	o.dirty = false
+}
+
+// Run publish the pending location in order until the outbox is closed.
+// When publishing fail, it is retried after the retry interval.
+func (o *Outbox) Run(ctx context.Context) {
+	o.running.Store(true)
+	defer close(o.closed)
+
+	var flush <-chan time.Time
+	if o.cfg.Fsync == FsyncInterval {
+		ticker := time.NewTicker(o.cfg.FsyncInterval)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+
+	for {
+		o.mu.Lock()
+		off, pending := o.readOff, o.writeOff > o.readOff
+		o.mu.Unlock()
+
+		if !pending {
+			select {
+			case <-o.done:
+				return
+			case <-ctx.Done():
+				return
+			case <-flush:
+				o.flush()
+			case <-o.notify:
+			}
+			continue
+		}
+
+		err := o.publish(ctx, off)
+		if err == nil {
+			continue
+		}
+
+		log.Warn().Err(err).Int64("pending", o.Pending()).Msg("failed to publish outbox location")
+		select {
+		case <-o.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-flush:
+			o.flush()
+		case <-time.After(o.cfg.RetryInterval):
+		}
+	}
+}
+
+// publish up to publishBatchSize records from off in order and commit them.
+// The locations between two trip events are published in a single batch.
+func (o *Outbox) publish(ctx context.Context, off int64) error {
+	o.mu.Lock()
+	end := o.writeOff
+	o.mu.Unlock()
+
+	var locs []track.Location
+	// sendLocations publish the locations read so far and commit up to next.
+	sendLocations := func(next int64) error {
+		if len(locs) == 0 {
+			return nil
+		}
+		if err := track.SendBatch(ctx, o.pub, locs); err != nil {
+			return err
+		}
+		stats.Add("published", int64(len(locs)))
+		locs = locs[:0]
+		return o.commit(next)
+	}
+
+	next := off
+	for i := 0; i < publishBatchSize && next < end; i++ {
+		r, n, err := o.read(next)
+		if err != nil {
+			if serr := sendLocations(next); serr != nil {
+				return serr
+			}
+			return err
+		}
+
+		if r.trip == nil {
+			locs = append(locs, r.location)
+			next = n
+			continue
+		}
+
+		if err := sendLocations(next); err != nil {
+			return err
+		}
+		// SendTrip only append trip event when the publisher is a TripSender.
+		if err := o.pub.(track.TripSender).SendTrip(ctx, *r.trip); err != nil {
+			return err
+		}
+		stats.Add("published", 1)
+		if err := o.commit(n); err != nil {
+			return err
+		}
+		next = n
+	}
+	return sendLocations(next)
+}
+
+// Close stop Run, flush and close the outbox files.
+// Location still pending is kept for the next run.
+func (o *Outbox) Close() error {
+	close(o.done)
+	if o.running.Load() {
+		<-o.closed
+	}
+
+	o.mu.Lock()
+	o.dirty = true
+	o.mu.Unlock()
+	o.flush()
+
+	if err := o.log.Close(); err != nil {
+		return err
+	}
+	return o.checkpoint.Close()
+}
@@ -0,0 +1,318 @@
+package outbox
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/codec"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSender records the sent location and fail while down is true.
+// A trip event is recorded with the number of location sent before it.
+type fakeSender struct {
+	mu    sync.Mutex
+	down  bool
+	sent  []float64
+	trips []int
+}
+
+func (s *fakeSender) Send(ctx context.Context, l track.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		return errors.New("broker unavailable")
+	}
+	s.sent = append(s.sent, l.Lat)
+	return nil
+}
+
+func (s *fakeSender) SendTrip(ctx context.Context, t track.Trip) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		return errors.New("broker unavailable")
+	}
+	s.trips = append(s.trips, len(s.sent))
+	return nil
+}
+
+func (s *fakeSender) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *fakeSender) sentLocations() []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]float64(nil), s.sent...)
+}
+
+// slowBatchSender takes delay for every call, like a kafka writer waiting for its batch timeout.
+type slowBatchSender struct {
+	fakeSender
+	delay time.Duration
+	calls int
+}
+
+func (s *slowBatchSender) Send(ctx context.Context, l track.Location) error {
+	return s.SendBatch(ctx, []track.Location{l})
+}
+
+func (s *slowBatchSender) SendBatch(ctx context.Context, locs []track.Location) error {
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("broker unavailable")
+	}
+	s.calls++
+	for _, l := range locs {
+		s.sent = append(s.sent, l.Lat)
+	}
+	return nil
+}
+
+func TestOutboxDrainInOrderAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	pub := &fakeSender{}
+	cfg := Config{Fsync: FsyncAlways, RetryInterval: 10 * time.Millisecond}
+
+	o, err := Open(dir, pub, cfg)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, o.Send(ctx, track.Location{Lat: 1}))
+
+	// broker is down, location is buffered.
+	pub.setDown(true)
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 2}), track.ErrBuffered)
+
+	// broker is back, but location 2 is still pending so location 3 must wait behind it.
+	pub.setDown(false)
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 3}), track.ErrBuffered)
+	assert.NoError(t, o.Close())
+
+	// pending location survive the restart.
+	o, err = Open(dir, pub, cfg)
+	assert.NoError(t, err)
+	assert.Greater(t, o.Pending(), int64(0))
+
+	go o.Run(ctx)
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, o.Close())
+
+	assert.Equal(t, []float64{1, 2, 3}, pub.sentLocations())
+
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	assert.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestOutboxTripAfterPendingLocation(t *testing.T) {
+	dir := t.TempDir()
+	pub := &fakeSender{down: true}
+	cfg := Config{Fsync: FsyncAlways, RetryInterval: 10 * time.Millisecond}
+
+	o, err := Open(dir, pub, cfg)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 1}), track.ErrBuffered)
+
+	// the broker is back, but the trip end must wait behind the pending location, even after a restart.
+	pub.setDown(false)
+	trip := track.Trip{ID: "t1", Bus: track.Bus{ID: "1"}, Status: track.TripEnded}
+	assert.ErrorIs(t, o.SendTrip(ctx, trip), track.ErrBuffered)
+	assert.NoError(t, o.Close())
+
+	o, err = Open(dir, pub, cfg)
+	assert.NoError(t, err)
+	go o.Run(ctx)
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+
+	// nothing is pending, the trip event is published right away.
+	assert.NoError(t, o.SendTrip(ctx, trip))
+	assert.NoError(t, o.Close())
+
+	assert.Equal(t, []float64{1}, pub.sentLocations())
+	pub.mu.Lock()
+	defer pub.mu.Unlock()
+	assert.Equal(t, []int{1, 1}, pub.trips)
+}
+
+func TestOutboxRecoverTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	pub := &fakeSender{down: true}
+
+	o, err := Open(dir, pub, Config{Fsync: FsyncNever})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, o.Send(context.Background(), track.Location{Lat: 1}), track.ErrBuffered)
+	size := o.Pending()
+	assert.NoError(t, o.Close())
+
+	// simulate a crash in the middle of writing the second record.
+	f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 0, 42, 1, 2})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	o, err = Open(dir, pub, Config{Fsync: FsyncNever})
+	assert.NoError(t, err)
+	assert.Equal(t, size, o.Pending())
+	assert.NoError(t, o.Close())
+}
+
+func TestOutboxRecoverCrashDuringCommit(t *testing.T) {
+	dir := t.TempDir()
+	pub := &fakeSender{down: true}
+	cfg := Config{Fsync: FsyncAlways, RetryInterval: 10 * time.Millisecond}
+	ctx := context.Background()
+
+	o, err := Open(dir, pub, cfg)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 1}), track.ErrBuffered)
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 2}), track.ErrBuffered)
+	size := o.Pending()
+	records, err := os.ReadFile(filepath.Join(dir, logFile))
+	assert.NoError(t, err)
+
+	pub.setDown(false)
+	go o.Run(ctx)
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, o.Close())
+
+	// crash after the checkpoint is reset and before the log is truncated,
+	// the records are published again.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, logFile), records, 0o644))
+	o, err = Open(dir, pub, cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, size, o.Pending())
+	go o.Run(ctx)
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, o.Close())
+	assert.Equal(t, []float64{1, 2, 1, 2}, pub.sentLocations())
+
+	// the log is truncated but the reset checkpoint never reached the disk.
+	cf, err := os.OpenFile(filepath.Join(dir, checkpointFile), os.O_WRONLY, 0o644)
+	assert.NoError(t, err)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(size))
+	_, err = cf.WriteAt(buf[:], 0)
+	assert.NoError(t, err)
+	assert.NoError(t, cf.Close())
+
+	o, err = Open(dir, pub, cfg)
+	assert.NoError(t, err)
+	assert.Zero(t, o.Pending())
+	assert.NoError(t, o.Send(ctx, track.Location{Lat: 3}))
+	assert.NoError(t, o.Close())
+	assert.Equal(t, []float64{1, 2, 1, 2, 3}, pub.sentLocations())
+}
+
+func TestOutboxFull(t *testing.T) {
+	o, err := Open(t.TempDir(), &fakeSender{down: true}, Config{MaxBytes: 100})
+	assert.NoError(t, err)
+
+	// a single record fit in 100 bytes, the second one doesn't.
+	var errs []error
+	for i := 0; i < 2; i++ {
+		errs = append(errs, o.Send(context.Background(), track.Location{Bus: track.Bus{ID: "1"}}))
+	}
+	assert.ErrorIs(t, errs[0], track.ErrBuffered)
+	assert.ErrorIs(t, errs[1], ErrFull)
+	assert.NoError(t, o.Close())
+}
+
+func TestOutboxDrainFasterThanInput(t *testing.T) {
+	pub := &slowBatchSender{fakeSender: fakeSender{down: true}, delay: 5 * time.Millisecond}
+	o, err := Open(t.TempDir(), pub, Config{Fsync: FsyncNever, RetryInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 2000; i++ {
+		assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: float64(i)}), track.ErrBuffered)
+	}
+
+	// the broker is back while new location keeps arriving behind the backlog,
+	// one by one the backlog would take 10 second to drain.
+	pub.setDown(false)
+	go o.Run(ctx)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 2000; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				o.Send(ctx, track.Location{Lat: float64(i)})
+			}
+		}
+	}()
+
+	assert.Eventually(t, func() bool { return len(pub.sentLocations()) >= 2000 }, time.Second, time.Millisecond)
+	close(stop)
+	<-done
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, o.Close())
+
+	sent := pub.sentLocations()
+	for i, lat := range sent {
+		assert.Equal(t, float64(i), lat)
+	}
+}
+
+func TestOutboxCompact(t *testing.T) {
+	dir := t.TempDir()
+	pub := &fakeSender{down: true}
+	// every record has the same size, the outbox holds 8 of them.
+	payload, err := codec.JSON{}.Encode(track.Location{Lat: 1})
+	assert.NoError(t, err)
+	maxBytes := 8 * int64(headerSize+len(payload))
+	o, err := Open(dir, pub, Config{Fsync: FsyncAlways, MaxBytes: maxBytes})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	for i := 0; i < 8; i++ {
+		assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: float64(i)}), track.ErrBuffered)
+	}
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 8}), ErrFull)
+
+	// the published records take half of the outbox, the log only keeps the pending one.
+	pub.setDown(false)
+	off := int64(0)
+	for i := 0; i < 5; i++ {
+		_, off, err = o.read(off)
+		assert.NoError(t, err)
+	}
+	pending := o.Pending() - off
+	assert.NoError(t, o.commit(off))
+	assert.Equal(t, pending, o.Pending())
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	assert.NoError(t, err)
+	assert.Equal(t, pending, info.Size())
+
+	// the pending records survive a restart and more location fit in the outbox.
+	assert.NoError(t, o.Close())
+	o, err = Open(dir, pub, Config{Fsync: FsyncAlways, MaxBytes: maxBytes, RetryInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, o.Send(ctx, track.Location{Lat: 8}), track.ErrBuffered)
+	go o.Run(ctx)
+	assert.Eventually(t, func() bool { return o.Pending() == 0 }, time.Second, time.Millisecond)
+	assert.NoError(t, o.Close())
+	assert.Equal(t, []float64{5, 6, 7, 8}, pub.sentLocations())
+}
@@ -0,0 +1,266 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: tracking/v1/service.proto
+
+package trackingv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	DriverService_PublishLocations_FullMethodName = "/tracking.v1.DriverService/PublishLocations"
+)
+
+// DriverServiceClient is the client API for DriverService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DriverServiceClient interface {
+	// PublishLocations stream the location of the bus, the result is returned once the driver close the stream.
+	PublishLocations(ctx context.Context, opts ...grpc.CallOption) (DriverService_PublishLocationsClient, error)
+}
+
+type driverServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDriverServiceClient(cc grpc.ClientConnInterface) DriverServiceClient {
+	return &driverServiceClient{cc}
+}
+
+func (c *driverServiceClient) PublishLocations(ctx context.Context, opts ...grpc.CallOption) (DriverService_PublishLocationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DriverService_ServiceDesc.Streams[0], DriverService_PublishLocations_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &driverServicePublishLocationsClient{stream}
+	return x, nil
+}
+
+type DriverService_PublishLocationsClient interface {
+	Send(*PublishLocationsRequest) error
+	CloseAndRecv() (*PublishLocationsResponse, error)
+	grpc.ClientStream
+}
+
+type driverServicePublishLocationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *driverServicePublishLocationsClient) Send(m *PublishLocationsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *driverServicePublishLocationsClient) CloseAndRecv() (*PublishLocationsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PublishLocationsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DriverServiceServer is the server API for DriverService service.
+// All implementations must embed UnimplementedDriverServiceServer
+// for forward compatibility
+type DriverServiceServer interface {
+	// PublishLocations stream the location of the bus, the result is returned once the driver close the stream.
+	PublishLocations(DriverService_PublishLocationsServer) error
+	mustEmbedUnimplementedDriverServiceServer()
+}
+
+// UnimplementedDriverServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDriverServiceServer struct {
+}
+
+func (UnimplementedDriverServiceServer) PublishLocations(DriverService_PublishLocationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method PublishLocations not implemented")
+}
+func (UnimplementedDriverServiceServer) mustEmbedUnimplementedDriverServiceServer() {}
+
+// UnsafeDriverServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DriverServiceServer will
+// result in compilation errors.
+type UnsafeDriverServiceServer interface {
+	mustEmbedUnimplementedDriverServiceServer()
+}
+
+func RegisterDriverServiceServer(s grpc.ServiceRegistrar, srv DriverServiceServer) {
+	s.RegisterService(&DriverService_ServiceDesc, srv)
+}
+
+func _DriverService_PublishLocations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DriverServiceServer).PublishLocations(&driverServicePublishLocationsServer{stream})
+}
+
+type DriverService_PublishLocationsServer interface {
+	SendAndClose(*PublishLocationsResponse) error
+	Recv() (*PublishLocationsRequest, error)
+	grpc.ServerStream
+}
+
+type driverServicePublishLocationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *driverServicePublishLocationsServer) SendAndClose(m *PublishLocationsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *driverServicePublishLocationsServer) Recv() (*PublishLocationsRequest, error) {
+	m := new(PublishLocationsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DriverService_ServiceDesc is the grpc.ServiceDesc for DriverService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DriverService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tracking.v1.DriverService",
+	HandlerType: (*DriverServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PublishLocations",
+			Handler:       _DriverService_PublishLocations_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "tracking/v1/service.proto",
+}
+
+const (
+	RiderService_WatchBuses_FullMethodName = "/tracking.v1.RiderService/WatchBuses"
+)
+
+// RiderServiceClient is the client API for RiderService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RiderServiceClient interface {
+	// WatchBuses stream the location and the trip event of the buses,
+	// starting with their trip in progress and last known location.
+	WatchBuses(ctx context.Context, in *WatchBusesRequest, opts ...grpc.CallOption) (RiderService_WatchBusesClient, error)
+}
+
+type riderServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRiderServiceClient(cc grpc.ClientConnInterface) RiderServiceClient {
+	return &riderServiceClient{cc}
+}
+
+func (c *riderServiceClient) WatchBuses(ctx context.Context, in *WatchBusesRequest, opts ...grpc.CallOption) (RiderService_WatchBusesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RiderService_ServiceDesc.Streams[0], RiderService_WatchBuses_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &riderServiceWatchBusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RiderService_WatchBusesClient interface {
+	Recv() (*WatchBusesResponse, error)
+	grpc.ClientStream
+}
+
+type riderServiceWatchBusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *riderServiceWatchBusesClient) Recv() (*WatchBusesResponse, error) {
+	m := new(WatchBusesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RiderServiceServer is the server API for RiderService service.
+// All implementations must embed UnimplementedRiderServiceServer
+// for forward compatibility
+type RiderServiceServer interface {
+	// WatchBuses stream the location and the trip event of the buses,
+	// starting with their trip in progress and last known location.
+	WatchBuses(*WatchBusesRequest, RiderService_WatchBusesServer) error
+	mustEmbedUnimplementedRiderServiceServer()
+}
+
+// UnimplementedRiderServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRiderServiceServer struct {
+}
+
+func (UnimplementedRiderServiceServer) WatchBuses(*WatchBusesRequest, RiderService_WatchBusesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBuses not implemented")
+}
+func (UnimplementedRiderServiceServer) mustEmbedUnimplementedRiderServiceServer() {}
+
+// UnsafeRiderServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RiderServiceServer will
+// result in compilation errors.
+type UnsafeRiderServiceServer interface {
+	mustEmbedUnimplementedRiderServiceServer()
+}
+
+func RegisterRiderServiceServer(s grpc.ServiceRegistrar, srv RiderServiceServer) {
+	s.RegisterService(&RiderService_ServiceDesc, srv)
+}
+
+func _RiderService_WatchBuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBusesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RiderServiceServer).WatchBuses(m, &riderServiceWatchBusesServer{stream})
+}
+
+type RiderService_WatchBusesServer interface {
+	Send(*WatchBusesResponse) error
+	grpc.ServerStream
+}
+
+type riderServiceWatchBusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *riderServiceWatchBusesServer) Send(m *WatchBusesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// RiderService_ServiceDesc is the grpc.ServiceDesc for RiderService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RiderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tracking.v1.RiderService",
+	HandlerType: (*RiderServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchBuses",
+			Handler:       _RiderService_WatchBuses_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tracking/v1/service.proto",
+}
@@ -0,0 +1,1001 @@
+// The subset of the GTFS Realtime specification (https://gtfs.org/realtime/reference/)
+// needed to export the vehicle positions. Field numbers and types are the ones of the
+// specification, so the feed can be read by any GTFS Realtime consumer.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.32.0
+// 	protoc        (unknown)
+// source: transit_realtime/gtfs-realtime.proto
+
+package gtfsrt
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FeedHeader_Incrementality int32
+
+const (
+	FeedHeader_FULL_DATASET FeedHeader_Incrementality = 0
+	FeedHeader_DIFFERENTIAL FeedHeader_Incrementality = 1
+)
+
+// Enum value maps for FeedHeader_Incrementality.
+var (
+	FeedHeader_Incrementality_name = map[int32]string{
+		0: "FULL_DATASET",
+		1: "DIFFERENTIAL",
+	}
+	FeedHeader_Incrementality_value = map[string]int32{
+		"FULL_DATASET": 0,
+		"DIFFERENTIAL": 1,
+	}
+)
+
+func (x FeedHeader_Incrementality) Enum() *FeedHeader_Incrementality {
+	p := new(FeedHeader_Incrementality)
+	*p = x
+	return p
+}
+
+func (x FeedHeader_Incrementality) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FeedHeader_Incrementality) Descriptor() protoreflect.EnumDescriptor {
+	return file_transit_realtime_gtfs_realtime_proto_enumTypes[0].Descriptor()
+}
+
+func (FeedHeader_Incrementality) Type() protoreflect.EnumType {
+	return &file_transit_realtime_gtfs_realtime_proto_enumTypes[0]
+}
+
+func (x FeedHeader_Incrementality) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *FeedHeader_Incrementality) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = FeedHeader_Incrementality(num)
+	return nil
+}
+
+// Deprecated: Use FeedHeader_Incrementality.Descriptor instead.
+func (FeedHeader_Incrementality) EnumDescriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type VehiclePosition_VehicleStopStatus int32
+
+const (
+	VehiclePosition_INCOMING_AT   VehiclePosition_VehicleStopStatus = 0
+	VehiclePosition_STOPPED_AT    VehiclePosition_VehicleStopStatus = 1
+	VehiclePosition_IN_TRANSIT_TO VehiclePosition_VehicleStopStatus = 2
+)
+
+// Enum value maps for VehiclePosition_VehicleStopStatus.
+var (
+	VehiclePosition_VehicleStopStatus_name = map[int32]string{
+		0: "INCOMING_AT",
+		1: "STOPPED_AT",
+		2: "IN_TRANSIT_TO",
+	}
+	VehiclePosition_VehicleStopStatus_value = map[string]int32{
+		"INCOMING_AT":   0,
+		"STOPPED_AT":    1,
+		"IN_TRANSIT_TO": 2,
+	}
+)
+
+func (x VehiclePosition_VehicleStopStatus) Enum() *VehiclePosition_VehicleStopStatus {
+	p := new(VehiclePosition_VehicleStopStatus)
+	*p = x
+	return p
+}
+
+func (x VehiclePosition_VehicleStopStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (VehiclePosition_VehicleStopStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_transit_realtime_gtfs_realtime_proto_enumTypes[1].Descriptor()
+}
+
+func (VehiclePosition_VehicleStopStatus) Type() protoreflect.EnumType {
+	return &file_transit_realtime_gtfs_realtime_proto_enumTypes[1]
+}
+
+func (x VehiclePosition_VehicleStopStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *VehiclePosition_VehicleStopStatus) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = VehiclePosition_VehicleStopStatus(num)
+	return nil
+}
+
+// Deprecated: Use VehiclePosition_VehicleStopStatus.Descriptor instead.
+func (VehiclePosition_VehicleStopStatus) EnumDescriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{3, 0}
+}
+
+type TripDescriptor_ScheduleRelationship int32
+
+const (
+	TripDescriptor_SCHEDULED   TripDescriptor_ScheduleRelationship = 0
+	TripDescriptor_ADDED       TripDescriptor_ScheduleRelationship = 1
+	TripDescriptor_UNSCHEDULED TripDescriptor_ScheduleRelationship = 2
+	TripDescriptor_CANCELED    TripDescriptor_ScheduleRelationship = 3
+)
+
+// Enum value maps for TripDescriptor_ScheduleRelationship.
+var (
+	TripDescriptor_ScheduleRelationship_name = map[int32]string{
+		0: "SCHEDULED",
+		1: "ADDED",
+		2: "UNSCHEDULED",
+		3: "CANCELED",
+	}
+	TripDescriptor_ScheduleRelationship_value = map[string]int32{
+		"SCHEDULED":   0,
+		"ADDED":       1,
+		"UNSCHEDULED": 2,
+		"CANCELED":    3,
+	}
+)
+
+func (x TripDescriptor_ScheduleRelationship) Enum() *TripDescriptor_ScheduleRelationship {
+	p := new(TripDescriptor_ScheduleRelationship)
+	*p = x
+	return p
+}
+
+func (x TripDescriptor_ScheduleRelationship) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TripDescriptor_ScheduleRelationship) Descriptor() protoreflect.EnumDescriptor {
+	return file_transit_realtime_gtfs_realtime_proto_enumTypes[2].Descriptor()
+}
+
+func (TripDescriptor_ScheduleRelationship) Type() protoreflect.EnumType {
+	return &file_transit_realtime_gtfs_realtime_proto_enumTypes[2]
+}
+
+func (x TripDescriptor_ScheduleRelationship) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *TripDescriptor_ScheduleRelationship) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = TripDescriptor_ScheduleRelationship(num)
+	return nil
+}
+
+// Deprecated: Use TripDescriptor_ScheduleRelationship.Descriptor instead.
+func (TripDescriptor_ScheduleRelationship) EnumDescriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{5, 0}
+}
+
+// FeedMessage is the content of a feed.
+type FeedMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Header *FeedHeader   `protobuf:"bytes,1,req,name=header" json:"header,omitempty"`
+	Entity []*FeedEntity `protobuf:"bytes,2,rep,name=entity" json:"entity,omitempty"`
+}
+
+func (x *FeedMessage) Reset() {
+	*x = FeedMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeedMessage) ProtoMessage() {}
+
+func (x *FeedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeedMessage.ProtoReflect.Descriptor instead.
+func (*FeedMessage) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FeedMessage) GetHeader() *FeedHeader {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+func (x *FeedMessage) GetEntity() []*FeedEntity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+// FeedHeader is the metadata of a feed.
+type FeedHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GtfsRealtimeVersion *string                    `protobuf:"bytes,1,req,name=gtfs_realtime_version,json=gtfsRealtimeVersion" json:"gtfs_realtime_version,omitempty"`
+	Incrementality      *FeedHeader_Incrementality `protobuf:"varint,2,opt,name=incrementality,enum=transit_realtime.FeedHeader_Incrementality,def=0" json:"incrementality,omitempty"`
+	// timestamp is when the content of the feed was created, in POSIX time.
+	Timestamp *uint64 `protobuf:"varint,3,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+// Default values for FeedHeader fields.
+const (
+	Default_FeedHeader_Incrementality = FeedHeader_FULL_DATASET
+)
+
+func (x *FeedHeader) Reset() {
+	*x = FeedHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeedHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeedHeader) ProtoMessage() {}
+
+func (x *FeedHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeedHeader.ProtoReflect.Descriptor instead.
+func (*FeedHeader) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FeedHeader) GetGtfsRealtimeVersion() string {
+	if x != nil && x.GtfsRealtimeVersion != nil {
+		return *x.GtfsRealtimeVersion
+	}
+	return ""
+}
+
+func (x *FeedHeader) GetIncrementality() FeedHeader_Incrementality {
+	if x != nil && x.Incrementality != nil {
+		return *x.Incrementality
+	}
+	return Default_FeedHeader_Incrementality
+}
+
+func (x *FeedHeader) GetTimestamp() uint64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+// FeedEntity is an entity of the feed, only vehicle position is supported.
+type FeedEntity struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        *string          `protobuf:"bytes,1,req,name=id" json:"id,omitempty"`
+	IsDeleted *bool            `protobuf:"varint,2,opt,name=is_deleted,json=isDeleted,def=0" json:"is_deleted,omitempty"`
+	Vehicle   *VehiclePosition `protobuf:"bytes,4,opt,name=vehicle" json:"vehicle,omitempty"`
+}
+
+// Default values for FeedEntity fields.
+const (
+	Default_FeedEntity_IsDeleted = bool(false)
+)
+
+func (x *FeedEntity) Reset() {
+	*x = FeedEntity{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FeedEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FeedEntity) ProtoMessage() {}
+
+func (x *FeedEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FeedEntity.ProtoReflect.Descriptor instead.
+func (*FeedEntity) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FeedEntity) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *FeedEntity) GetIsDeleted() bool {
+	if x != nil && x.IsDeleted != nil {
+		return *x.IsDeleted
+	}
+	return Default_FeedEntity_IsDeleted
+}
+
+func (x *FeedEntity) GetVehicle() *VehiclePosition {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+// VehiclePosition is the realtime position of a vehicle.
+type VehiclePosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Trip                *TripDescriptor                    `protobuf:"bytes,1,opt,name=trip" json:"trip,omitempty"`
+	Vehicle             *VehicleDescriptor                 `protobuf:"bytes,8,opt,name=vehicle" json:"vehicle,omitempty"`
+	Position            *Position                          `protobuf:"bytes,2,opt,name=position" json:"position,omitempty"`
+	CurrentStopSequence *uint32                            `protobuf:"varint,3,opt,name=current_stop_sequence,json=currentStopSequence" json:"current_stop_sequence,omitempty"`
+	StopId              *string                            `protobuf:"bytes,7,opt,name=stop_id,json=stopId" json:"stop_id,omitempty"`
+	CurrentStatus       *VehiclePosition_VehicleStopStatus `protobuf:"varint,4,opt,name=current_status,json=currentStatus,enum=transit_realtime.VehiclePosition_VehicleStopStatus,def=2" json:"current_status,omitempty"`
+	// timestamp is when the position was measured, in POSIX time.
+	Timestamp *uint64 `protobuf:"varint,5,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+// Default values for VehiclePosition fields.
+const (
+	Default_VehiclePosition_CurrentStatus = VehiclePosition_IN_TRANSIT_TO
+)
+
+func (x *VehiclePosition) Reset() {
+	*x = VehiclePosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VehiclePosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehiclePosition) ProtoMessage() {}
+
+func (x *VehiclePosition) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehiclePosition.ProtoReflect.Descriptor instead.
+func (*VehiclePosition) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VehiclePosition) GetTrip() *TripDescriptor {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+func (x *VehiclePosition) GetVehicle() *VehicleDescriptor {
+	if x != nil {
+		return x.Vehicle
+	}
+	return nil
+}
+
+func (x *VehiclePosition) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *VehiclePosition) GetCurrentStopSequence() uint32 {
+	if x != nil && x.CurrentStopSequence != nil {
+		return *x.CurrentStopSequence
+	}
+	return 0
+}
+
+func (x *VehiclePosition) GetStopId() string {
+	if x != nil && x.StopId != nil {
+		return *x.StopId
+	}
+	return ""
+}
+
+func (x *VehiclePosition) GetCurrentStatus() VehiclePosition_VehicleStopStatus {
+	if x != nil && x.CurrentStatus != nil {
+		return *x.CurrentStatus
+	}
+	return Default_VehiclePosition_CurrentStatus
+}
+
+func (x *VehiclePosition) GetTimestamp() uint64 {
+	if x != nil && x.Timestamp != nil {
+		return *x.Timestamp
+	}
+	return 0
+}
+
+// Position is a geographic position of a vehicle.
+type Position struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Latitude  *float32 `protobuf:"fixed32,1,req,name=latitude" json:"latitude,omitempty"`
+	Longitude *float32 `protobuf:"fixed32,2,req,name=longitude" json:"longitude,omitempty"`
+	// bearing is in degree clockwise from true north.
+	Bearing *float32 `protobuf:"fixed32,3,opt,name=bearing" json:"bearing,omitempty"`
+	// odometer is in meter.
+	Odometer *float64 `protobuf:"fixed64,4,opt,name=odometer" json:"odometer,omitempty"`
+	// speed is in meter per second.
+	Speed *float32 `protobuf:"fixed32,5,opt,name=speed" json:"speed,omitempty"`
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Position) GetLatitude() float32 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *Position) GetLongitude() float32 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *Position) GetBearing() float32 {
+	if x != nil && x.Bearing != nil {
+		return *x.Bearing
+	}
+	return 0
+}
+
+func (x *Position) GetOdometer() float64 {
+	if x != nil && x.Odometer != nil {
+		return *x.Odometer
+	}
+	return 0
+}
+
+func (x *Position) GetSpeed() float32 {
+	if x != nil && x.Speed != nil {
+		return *x.Speed
+	}
+	return 0
+}
+
+// TripDescriptor identifies the trip of a vehicle.
+type TripDescriptor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TripId               *string                              `protobuf:"bytes,1,opt,name=trip_id,json=tripId" json:"trip_id,omitempty"`
+	RouteId              *string                              `protobuf:"bytes,5,opt,name=route_id,json=routeId" json:"route_id,omitempty"`
+	DirectionId          *uint32                              `protobuf:"varint,6,opt,name=direction_id,json=directionId" json:"direction_id,omitempty"`
+	StartTime            *string                              `protobuf:"bytes,2,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	StartDate            *string                              `protobuf:"bytes,3,opt,name=start_date,json=startDate" json:"start_date,omitempty"`
+	ScheduleRelationship *TripDescriptor_ScheduleRelationship `protobuf:"varint,4,opt,name=schedule_relationship,json=scheduleRelationship,enum=transit_realtime.TripDescriptor_ScheduleRelationship" json:"schedule_relationship,omitempty"`
+}
+
+func (x *TripDescriptor) Reset() {
+	*x = TripDescriptor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TripDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripDescriptor) ProtoMessage() {}
+
+func (x *TripDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripDescriptor.ProtoReflect.Descriptor instead.
+func (*TripDescriptor) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TripDescriptor) GetTripId() string {
+	if x != nil && x.TripId != nil {
+		return *x.TripId
+	}
+	return ""
+}
+
+func (x *TripDescriptor) GetRouteId() string {
+	if x != nil && x.RouteId != nil {
+		return *x.RouteId
+	}
+	return ""
+}
+
+func (x *TripDescriptor) GetDirectionId() uint32 {
+	if x != nil && x.DirectionId != nil {
+		return *x.DirectionId
+	}
+	return 0
+}
+
+func (x *TripDescriptor) GetStartTime() string {
+	if x != nil && x.StartTime != nil {
+		return *x.StartTime
+	}
+	return ""
+}
+
+func (x *TripDescriptor) GetStartDate() string {
+	if x != nil && x.StartDate != nil {
+		return *x.StartDate
+	}
+	return ""
+}
+
+func (x *TripDescriptor) GetScheduleRelationship() TripDescriptor_ScheduleRelationship {
+	if x != nil && x.ScheduleRelationship != nil {
+		return *x.ScheduleRelationship
+	}
+	return TripDescriptor_SCHEDULED
+}
+
+// VehicleDescriptor identifies a vehicle.
+type VehicleDescriptor struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id           *string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Label        *string `protobuf:"bytes,2,opt,name=label" json:"label,omitempty"`
+	LicensePlate *string `protobuf:"bytes,3,opt,name=license_plate,json=licensePlate" json:"license_plate,omitempty"`
+}
+
+func (x *VehicleDescriptor) Reset() {
+	*x = VehicleDescriptor{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VehicleDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehicleDescriptor) ProtoMessage() {}
+
+func (x *VehicleDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_transit_realtime_gtfs_realtime_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehicleDescriptor.ProtoReflect.Descriptor instead.
+func (*VehicleDescriptor) Descriptor() ([]byte, []int) {
+	return file_transit_realtime_gtfs_realtime_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VehicleDescriptor) GetId() string {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return ""
+}
+
+func (x *VehicleDescriptor) GetLabel() string {
+	if x != nil && x.Label != nil {
+		return *x.Label
+	}
+	return ""
+}
+
+func (x *VehicleDescriptor) GetLicensePlate() string {
+	if x != nil && x.LicensePlate != nil {
+		return *x.LicensePlate
+	}
+	return ""
+}
+
+var File_transit_realtime_gtfs_realtime_proto protoreflect.FileDescriptor
+
+var file_transit_realtime_gtfs_realtime_proto_rawDesc = []byte{
+	0x0a, 0x24, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69,
+	0x6d, 0x65, 0x2f, 0x67, 0x74, 0x66, 0x73, 0x2d, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x10, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f,
+	0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x79, 0x0a, 0x0b, 0x46, 0x65, 0x65, 0x64,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x34, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69,
+	0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x46, 0x65, 0x65, 0x64, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x34, 0x0a,
+	0x06, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65,
+	0x2e, 0x46, 0x65, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x52, 0x06, 0x65, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x22, 0xf7, 0x01, 0x0a, 0x0a, 0x46, 0x65, 0x65, 0x64, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x12, 0x32, 0x0a, 0x15, 0x67, 0x74, 0x66, 0x73, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x02, 0x28,
+	0x09, 0x52, 0x13, 0x67, 0x74, 0x66, 0x73, 0x52, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x61, 0x0a, 0x0e, 0x69, 0x6e, 0x63, 0x72, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2b,
+	0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d,
+	0x65, 0x2e, 0x46, 0x65, 0x65, 0x64, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x2e, 0x49, 0x6e, 0x63,
+	0x72, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x3a, 0x0c, 0x46, 0x55, 0x4c,
+	0x4c, 0x5f, 0x44, 0x41, 0x54, 0x41, 0x53, 0x45, 0x54, 0x52, 0x0e, 0x69, 0x6e, 0x63, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x34, 0x0a, 0x0e, 0x49, 0x6e, 0x63, 0x72, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x10, 0x0a, 0x0c, 0x46, 0x55, 0x4c,
+	0x4c, 0x5f, 0x44, 0x41, 0x54, 0x41, 0x53, 0x45, 0x54, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x44,
+	0x49, 0x46, 0x46, 0x45, 0x52, 0x45, 0x4e, 0x54, 0x49, 0x41, 0x4c, 0x10, 0x01, 0x22, 0x7f, 0x0a,
+	0x0a, 0x46, 0x65, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x02, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x24, 0x0a, 0x0a, 0x69,
+	0x73, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x3a,
+	0x05, 0x66, 0x61, 0x6c, 0x73, 0x65, 0x52, 0x09, 0x69, 0x73, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x12, 0x3b, 0x0a, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61,
+	0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x22, 0xdd,
+	0x03, 0x0a, 0x0f, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x34, 0x0a, 0x04, 0x74, 0x72, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74,
+	0x69, 0x6d, 0x65, 0x2e, 0x54, 0x72, 0x69, 0x70, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x6f, 0x72, 0x52, 0x04, 0x74, 0x72, 0x69, 0x70, 0x12, 0x3d, 0x0a, 0x07, 0x76, 0x65, 0x68, 0x69,
+	0x63, 0x6c, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x56, 0x65, 0x68,
+	0x69, 0x63, 0x6c, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x52, 0x07,
+	0x76, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x32, 0x0a, 0x15, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x6f, 0x70, 0x5f,
+	0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x6f, 0x70, 0x53, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x74, 0x6f, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x6f, 0x70, 0x49, 0x64, 0x12, 0x69, 0x0a, 0x0e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x33, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72,
+	0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x50,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65, 0x53,
+	0x74, 0x6f, 0x70, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x3a, 0x0d, 0x49, 0x4e, 0x5f, 0x54, 0x52,
+	0x41, 0x4e, 0x53, 0x49, 0x54, 0x5f, 0x54, 0x4f, 0x52, 0x0d, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x47, 0x0a, 0x11, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c, 0x65,
+	0x53, 0x74, 0x6f, 0x70, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e,
+	0x43, 0x4f, 0x4d, 0x49, 0x4e, 0x47, 0x5f, 0x41, 0x54, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x53,
+	0x54, 0x4f, 0x50, 0x50, 0x45, 0x44, 0x5f, 0x41, 0x54, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x49,
+	0x4e, 0x5f, 0x54, 0x52, 0x41, 0x4e, 0x53, 0x49, 0x54, 0x5f, 0x54, 0x4f, 0x10, 0x02, 0x22, 0x90,
+	0x01, 0x0a, 0x08, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c,
+	0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x02, 0x52, 0x08, 0x6c,
+	0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69,
+	0x74, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x02, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67,
+	0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x07, 0x62, 0x65, 0x61, 0x72, 0x69, 0x6e, 0x67, 0x12,
+	0x1a, 0x0a, 0x08, 0x6f, 0x64, 0x6f, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x6f, 0x64, 0x6f, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x70, 0x65, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x02, 0x52, 0x05, 0x73, 0x70, 0x65, 0x65,
+	0x64, 0x22, 0xe2, 0x02, 0x0a, 0x0e, 0x54, 0x72, 0x69, 0x70, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x6f, 0x72, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x72, 0x69, 0x70, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x72, 0x69, 0x70, 0x49, 0x64, 0x12, 0x19, 0x0a,
+	0x08, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x69, 0x72, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b,
+	0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x44, 0x61, 0x74, 0x65, 0x12, 0x6a, 0x0a, 0x15, 0x73, 0x63, 0x68,
+	0x65, 0x64, 0x75, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68,
+	0x69, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x35, 0x2e, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x69, 0x74, 0x5f, 0x72, 0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x2e, 0x54, 0x72, 0x69, 0x70,
+	0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x52,
+	0x14, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x68, 0x69, 0x70, 0x22, 0x4f, 0x0a, 0x14, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x12, 0x0d, 0x0a,
+	0x09, 0x53, 0x43, 0x48, 0x45, 0x44, 0x55, 0x4c, 0x45, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05,
+	0x41, 0x44, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x43, 0x48,
+	0x45, 0x44, 0x55, 0x4c, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x41, 0x4e, 0x43,
+	0x45, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x22, 0x5e, 0x0a, 0x11, 0x56, 0x65, 0x68, 0x69, 0x63, 0x6c,
+	0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x61, 0x62, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73, 0x65, 0x5f, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6c, 0x69, 0x63, 0x65, 0x6e, 0x73,
+	0x65, 0x50, 0x6c, 0x61, 0x74, 0x65, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x61, 0x66, 0x69, 0x6d, 0x75, 0x68, 0x61, 0x6d, 0x6d, 0x61,
+	0x64, 0x30, 0x31, 0x2f, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x2d, 0x61, 0x70, 0x70,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x69, 0x74, 0x5f, 0x72,
+	0x65, 0x61, 0x6c, 0x74, 0x69, 0x6d, 0x65, 0x3b, 0x67, 0x74, 0x66, 0x73, 0x72, 0x74,
+}
+
+var (
+	file_transit_realtime_gtfs_realtime_proto_rawDescOnce sync.Once
+	file_transit_realtime_gtfs_realtime_proto_rawDescData = file_transit_realtime_gtfs_realtime_proto_rawDesc
+)
+
+func file_transit_realtime_gtfs_realtime_proto_rawDescGZIP() []byte {
+	file_transit_realtime_gtfs_realtime_proto_rawDescOnce.Do(func() {
+		file_transit_realtime_gtfs_realtime_proto_rawDescData = protoimpl.X.CompressGZIP(file_transit_realtime_gtfs_realtime_proto_rawDescData)
+	})
+	return file_transit_realtime_gtfs_realtime_proto_rawDescData
+}
+
+var file_transit_realtime_gtfs_realtime_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_transit_realtime_gtfs_realtime_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_transit_realtime_gtfs_realtime_proto_goTypes = []interface{}{
+	(FeedHeader_Incrementality)(0),           // 0: transit_realtime.FeedHeader.Incrementality
+	(VehiclePosition_VehicleStopStatus)(0),   // 1: transit_realtime.VehiclePosition.VehicleStopStatus
+	(TripDescriptor_ScheduleRelationship)(0), // 2: transit_realtime.TripDescriptor.ScheduleRelationship
+	(*FeedMessage)(nil),                      // 3: transit_realtime.FeedMessage
+	(*FeedHeader)(nil),                       // 4: transit_realtime.FeedHeader
+	(*FeedEntity)(nil),                       // 5: transit_realtime.FeedEntity
+	(*VehiclePosition)(nil),                  // 6: transit_realtime.VehiclePosition
+	(*Position)(nil),                         // 7: transit_realtime.Position
+	(*TripDescriptor)(nil),                   // 8: transit_realtime.TripDescriptor
+	(*VehicleDescriptor)(nil),                // 9: transit_realtime.VehicleDescriptor
+}
+var file_transit_realtime_gtfs_realtime_proto_depIdxs = []int32{
+	4, // 0: transit_realtime.FeedMessage.header:type_name -> transit_realtime.FeedHeader
+	5, // 1: transit_realtime.FeedMessage.entity:type_name -> transit_realtime.FeedEntity
+	0, // 2: transit_realtime.FeedHeader.incrementality:type_name -> transit_realtime.FeedHeader.Incrementality
+	6, // 3: transit_realtime.FeedEntity.vehicle:type_name -> transit_realtime.VehiclePosition
+	8, // 4: transit_realtime.VehiclePosition.trip:type_name -> transit_realtime.TripDescriptor
+	9, // 5: transit_realtime.VehiclePosition.vehicle:type_name -> transit_realtime.VehicleDescriptor
+	7, // 6: transit_realtime.VehiclePosition.position:type_name -> transit_realtime.Position
+	1, // 7: transit_realtime.VehiclePosition.current_status:type_name -> transit_realtime.VehiclePosition.VehicleStopStatus
+	2, // 8: transit_realtime.TripDescriptor.schedule_relationship:type_name -> transit_realtime.TripDescriptor.ScheduleRelationship
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_transit_realtime_gtfs_realtime_proto_init() }
+func file_transit_realtime_gtfs_realtime_proto_init() {
+	if File_transit_realtime_gtfs_realtime_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeedMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeedHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FeedEntity); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VehiclePosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Position); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TripDescriptor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_transit_realtime_gtfs_realtime_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VehicleDescriptor); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_transit_realtime_gtfs_realtime_proto_rawDesc,
+			NumEnums:      3,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transit_realtime_gtfs_realtime_proto_goTypes,
+		DependencyIndexes: file_transit_realtime_gtfs_realtime_proto_depIdxs,
+		EnumInfos:         file_transit_realtime_gtfs_realtime_proto_enumTypes,
+		MessageInfos:      file_transit_realtime_gtfs_realtime_proto_msgTypes,
+	}.Build()
+	File_transit_realtime_gtfs_realtime_proto = out.File
+	file_transit_realtime_gtfs_realtime_proto_rawDesc = nil
+	file_transit_realtime_gtfs_realtime_proto_goTypes = nil
+	file_transit_realtime_gtfs_realtime_proto_depIdxs = nil
+}
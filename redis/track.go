@@ -0,0 +1,231 @@
+// Package redis publish and consume location through a Redis Stream.
+// Every tracking-service instance read the stream from its own position without consumer group,
+// so all of them receive every location.
+package redis
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/rafimuhammad01/tracking-app/broker"
+	"github.com/rafimuhammad01/tracking-app/codec"
+	"github.com/rafimuhammad01/tracking-app/track"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// Stream entry fields.
+const (
+	FieldBusID   = "bus_id"
+	FieldPayload = "payload"
+)
+
+const (
+	readCount   = 100
+	readBlock   = 5 * time.Second
+	readBackoff = time.Second
+)
+
+// stats exposes the redis counters through expvar (/debug/vars).
+var stats = expvar.NewMap("redis")
+
+// Tracker publish and consume location through redis stream.
+type Tracker struct {
+	client   *redis.Client
+	stream   string
+	maxLen   int64
+	codec    codec.Codec
+	receiver broker.Receiver
+
+	done chan struct{}
+}
+
+func (t *Tracker) Send(ctx context.Context, l track.Location) error {
+	args, err := t.addArgs(l)
+	if err != nil {
+		return err
+	}
+
+	if err := t.client.XAdd(ctx, args).Err(); err != nil {
+		log.Debug().Err(err).Msg("failed to add location to stream")
+		return err
+	}
+
+	return nil
+}
+
+// SendBatch add every location to the stream in a single pipeline.
+func (t *Tracker) SendBatch(ctx context.Context, locs []track.Location) error {
+	pipe := t.client.Pipeline()
+	for _, l := range locs {
+		args, err := t.addArgs(l)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, args)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Debug().Err(err).Int("size", len(locs)).Msg("failed to add location batch to stream")
+		return err
+	}
+
+	return nil
+}
+
+// SendTrip add the trip event to the same stream as the location.
+func (t *Tracker) SendTrip(ctx context.Context, trip track.Trip) error {
+	b, err := codec.EncodeTrip(trip)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal trip")
+		return err
+	}
+
+	if err := t.client.XAdd(ctx, t.entry(trip.Bus.ID, codec.ContentTypeTrip, b)).Err(); err != nil {
+		log.Debug().Err(err).Msg("failed to add trip to stream")
+		return err
+	}
+
+	return nil
+}
+
+func (t *Tracker) addArgs(l track.Location) (*redis.XAddArgs, error) {
+	b, err := t.codec.Encode(l)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to marshal location")
+		return nil, err
+	}
+
+	return t.entry(l.Bus.ID, t.codec.ContentType(), b), nil
+}
+
+func (t *Tracker) entry(busID, contentType string, payload []byte) *redis.XAddArgs {
+	return &redis.XAddArgs{
+		Stream: t.stream,
+		MaxLen: t.maxLen,
+		Approx: t.maxLen > 0,
+		Values: map[string]interface{}{
+			FieldBusID:              busID,
+			codec.HeaderContentType: contentType,
+			FieldPayload:            payload,
+		},
+	}
+}
+
+// Listen pass every new entry of the stream to the receiver until the tracker is closed.
+// Entries added before Listen is called are skipped.
+func (t *Tracker) Listen(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-t.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	lastID := "$"
+	for {
+		streams, err := t.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{t.stream, lastID},
+			Count:   readCount,
+			Block:   readBlock,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Info().Msg("connection closed")
+				return
+			}
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			log.Error().Err(err).Msg("failed to read stream")
+			time.Sleep(readBackoff)
+			continue
+		}
+
+		for _, s := range streams {
+			for _, m := range s.Messages {
+				lastID = m.ID
+
+				contentType, _ := m.Values[codec.HeaderContentType].(string)
+				payload, _ := m.Values[FieldPayload].(string)
+				if err := t.receive(contentType, []byte(payload)); err != nil {
+					stats.Add("decode_failures", 1)
+					log.Error().Err(err).Str("id", m.ID).Msg("failed to unmarshal message")
+				}
+			}
+		}
+	}
+}
+
+// receive decode the entry payload with the codec of its content type and pass it to the receiver.
+func (t *Tracker) receive(contentType string, b []byte) error {
+	if contentType == codec.ContentTypeTrip {
+		trip, err := codec.DecodeTrip(b)
+		if err != nil {
+			return err
+		}
+		t.receiver.ReceiveTrip(trip)
+		return nil
+	}
+
+	loc, err := codec.Decode(contentType, b)
+	if err != nil {
+		return err
+	}
+	t.receiver.Receive(loc)
+	return nil
+}
+
+// Close stop the listener and close the client.
+func (t *Tracker) Close() error {
+	select {
+	case <-t.done:
+		return nil
+	default:
+		close(t.done)
+	}
+	return t.client.Close()
+}
+
+type opts func(*Tracker)
+
+func NewTracker(client *redis.Client, stream string, opts ...opts) *Tracker {
+	t := Tracker{
+		client: client,
+		stream: stream,
+		codec:  codec.JSON{},
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(&t)
+	}
+
+	return &t
+}
+
+func WithReceiver(rc broker.Receiver) opts {
+	return func(t *Tracker) {
+		t.receiver = rc
+	}
+}
+
+// WithCodec will encode the sent location with c, JSON is used by default.
+// Received message is always decoded by its content type header.
+func WithCodec(c codec.Codec) opts {
+	return func(t *Tracker) {
+		t.codec = c
+	}
+}
+
+// WithMaxLen will trim the stream to approximately n entries, zero means unlimited.
+func WithMaxLen(n int64) opts {
+	return func(t *Tracker) {
+		t.maxLen = n
+	}
+}
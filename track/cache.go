@@ -0,0 +1,93 @@
+package track
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// cache keeps the latest location of every bus.
+// An entry expires ttl after it is received, so a bus that stop reporting
+// eventually disappear instead of being shown at its last position forever.
+type cache struct {
+	mu        sync.RWMutex
+	locations map[string]cacheEntry
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+type cacheEntry struct {
+	l         Location
+	expiresAt time.Time
+}
+
+// newCache create a cache, zero or negative ttl means the location never expires.
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		locations: make(map[string]cacheEntry),
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+func (c *cache) set(l Location) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+	c.locations[l.Bus.ID] = cacheEntry{l: l, expiresAt: expiresAt}
+}
+
+func (c *cache) get(busID string) (Location, bool) {
+	c.mu.RLock()
+	e, ok := c.locations[busID]
+	c.mu.RUnlock()
+
+	if !ok {
+		return Location{}, false
+	}
+
+	if c.expired(e) {
+		c.mu.Lock()
+		// the entry could be refreshed while we are waiting for the lock.
+		if e, ok := c.locations[busID]; ok && c.expired(e) {
+			delete(c.locations, busID)
+		}
+		c.mu.Unlock()
+		return Location{}, false
+	}
+
+	return e.l, true
+}
+
+// all returns the location of every bus ordered by bus id and remove the expired one,
+// so a bus that is never asked again doesn't stay in the cache.
+func (c *cache) all() []Location {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	locs := make([]Location, 0, len(c.locations))
+	for id, e := range c.locations {
+		if c.expired(e) {
+			delete(c.locations, id)
+			continue
+		}
+		locs = append(locs, e.l)
+	}
+	sort.Slice(locs, func(i, j int) bool { return locs[i].Bus.ID < locs[j].Bus.ID })
+	return locs
+}
+
+func (c *cache) delete(busID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.locations, busID)
+}
+
+func (c *cache) expired(e cacheEntry) bool {
+	return !e.expiresAt.IsZero() && !c.now().Before(e.expiresAt)
+}
@@ -0,0 +1,80 @@
+package track
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Now()
+	c := newCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.set(Location{Lat: 1, Bus: Bus{ID: "1"}})
+
+	l, ok := c.get("1")
+	assert.True(t, ok)
+	assert.Equal(t, float64(1), l.Lat)
+
+	_, ok = c.get("2")
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.get("1")
+	assert.False(t, ok)
+	assert.Empty(t, c.locations)
+
+	// all remove the expired location of the bus never asked again.
+	c.set(Location{Lat: 2, Bus: Bus{ID: "2"}})
+	now = now.Add(time.Minute / 2)
+	c.set(Location{Lat: 3, Bus: Bus{ID: "3"}})
+	now = now.Add(time.Minute / 2)
+	locs := c.all()
+	assert.Len(t, locs, 1)
+	assert.Equal(t, float64(3), locs[0].Lat)
+	assert.NotContains(t, c.locations, "2")
+}
+
+func TestTrackerSnapshotOnRegister(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(time.Minute))
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "1"}})
+	tracker.Receive(Location{Lat: 2, Bus: Bus{ID: "2"}})
+	tracker.Receive(Location{Lat: 3, Bus: Bus{ID: "1"}})
+
+	l := make(chan Update)
+	hd, err := tracker.Register(Customer{ID: "1"}, Subscription{BusIDs: []string{"1", "3"}}, l)
+	assert.NoError(t, err)
+
+	// the latest location of bus 1 is delivered before any live location.
+	assert.Equal(t, float64(3), (<-l).Location.Lat)
+
+	tracker.Receive(Location{Lat: 4, Bus: Bus{ID: "3"}})
+	assert.Equal(t, float64(4), (<-l).Location.Lat)
+
+	last, ok := tracker.LastLocation("3")
+	assert.True(t, ok)
+	assert.Equal(t, float64(4), last.Lat)
+
+	tracker.Unregister(hd)
+}
+
+func TestTrackerSnapshotSince(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(time.Minute))
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "1"}, Timestamp: now})
+	tracker.Receive(Location{Lat: 2, Bus: Bus{ID: "2"}, Timestamp: now.Add(-time.Second / 2)})
+
+	l := make(chan Update)
+	hd, err := tracker.Register(Customer{ID: "1"}, Subscription{BusIDs: []string{"1", "2"}, Since: map[string]time.Time{"1": now, "2": now.Add(-time.Second)}}, l)
+	assert.NoError(t, err)
+
+	// the client already has the location of bus 1, bus 2 is older than bus 1 but newer than the client has.
+	assert.Equal(t, float64(2), (<-l).Location.Lat)
+
+	tracker.Receive(Location{Lat: 3, Bus: Bus{ID: "1"}, Timestamp: now.Add(2 * time.Second)})
+	assert.Equal(t, float64(3), (<-l).Location.Lat)
+
+	tracker.Unregister(hd)
+}
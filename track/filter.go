@@ -0,0 +1,226 @@
+package track
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultAccuracy is the accuracy in meter assumed by the kalman filter when the device doesn't send any.
+	defaultAccuracy = 10.0
+	minAccuracy     = 1.0
+
+	// stationarySpeed is the speed in meter per second under which a bus reporting its speed is considered stopped.
+	stationarySpeed = 0.5
+
+	// diagnosticsTimeout bound the time spent sending a rejected location to the diagnostics sender.
+	diagnosticsTimeout = 5 * time.Second
+
+	// maxSpeedRejections is the number of consecutive location of a bus rejected by the max speed filter
+	// after which the next one is accepted, so an outlier can't stay the previous location forever.
+	maxSpeedRejections = 3
+
+	// kalmanStateTTL is how long the kalman filter keeps the state of a bus that stopped reporting.
+	kalmanStateTTL = 10 * time.Minute
+)
+
+// Filter check the location of a bus before it is broadcast, it can reject the location or adjust it.
+// prev is the latest location of the same bus accepted by the chain, nil for the first location of the bus.
+// The filters of a chain are never called concurrently for the same bus.
+type Filter interface {
+	Filter(prev *Location, l Location) (Location, error)
+}
+
+// FilterFunc adapt a function to Filter.
+type FilterFunc func(prev *Location, l Location) (Location, error)
+
+func (f FilterFunc) Filter(prev *Location, l Location) (Location, error) {
+	return f(prev, l)
+}
+
+// RejectError is returned by a Filter rejecting the location.
+type RejectError struct {
+	Filter string
+	Reason string
+}
+
+func (e *RejectError) Error() string {
+	return "location rejected by " + e.Filter + " filter: " + e.Reason
+}
+
+// filterChain run the filters in order for every location, per bus.
+// A location rejected by a filter is not given to the next ones and doesn't become prev.
+type filterChain struct {
+	filters []Filter
+	shards  []*filterShard
+}
+
+type filterShard struct {
+	mu   sync.Mutex // serialize the location of the buses of the shard
+	last map[string]Location
+}
+
+func newFilterChain(filters []Filter) *filterChain {
+	c := &filterChain{filters: filters, shards: make([]*filterShard, defaultShards)}
+	for i := range c.shards {
+		c.shards[i] = &filterShard{last: make(map[string]Location)}
+	}
+	return c
+}
+
+// apply returns the location adjusted by the filters, or the first error returned by a filter.
+func (c *filterChain) apply(l Location) (Location, error) {
+	s := c.shards[shardOf(l.Bus.ID, len(c.shards))]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var prev *Location
+	if last, ok := s.last[l.Bus.ID]; ok {
+		prev = &last
+	}
+
+	out := l
+	for _, f := range c.filters {
+		var err error
+		if out, err = f.Filter(prev, out); err != nil {
+			return l, err
+		}
+	}
+
+	s.last[l.Bus.ID] = out
+	return out, nil
+}
+
+// maxSpeedFilter count the consecutive rejected location of every bus.
+type maxSpeedFilter struct {
+	maxSpeed float64
+
+	mu       sync.Mutex
+	rejected map[string]int
+}
+
+// NewMaxSpeedFilter reject location that would need the bus to travel faster than maxSpeed meter per second
+// since its previous location. A bus whose previous location is the outlier is accepted again
+// once enough time has passed for the distance to be plausible, or after 3 consecutive rejected location.
+func NewMaxSpeedFilter(maxSpeed float64) Filter {
+	return &maxSpeedFilter{maxSpeed: maxSpeed, rejected: make(map[string]int)}
+}
+
+func (f *maxSpeedFilter) Filter(prev *Location, l Location) (Location, error) {
+	if prev == nil {
+		return l, nil
+	}
+
+	elapsed := l.Timestamp.Sub(prev.Timestamp).Seconds()
+	if elapsed <= 0 {
+		return l, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	speed := distance(prev.Lat, prev.Long, l.Lat, l.Long) / elapsed
+	if speed <= f.maxSpeed || f.rejected[l.Bus.ID] >= maxSpeedRejections {
+		delete(f.rejected, l.Bus.ID)
+		return l, nil
+	}
+
+	f.rejected[l.Bus.ID]++
+	return l, &RejectError{Filter: "max_speed", Reason: fmt.Sprintf("implied speed %.1f m/s is above %g m/s", speed, f.maxSpeed)}
+}
+
+// NewStationaryFilter keep a stopped bus at its previous position instead of following the GPS drift,
+// as long as it stays within radius meter of it. A bus reporting its speed is stopped
+// when it is slower than 0.5 m/s.
+func NewStationaryFilter(radius float64) Filter {
+	return FilterFunc(func(prev *Location, l Location) (Location, error) {
+		if prev == nil || (l.Speed != nil && *l.Speed >= stationarySpeed) {
+			return l, nil
+		}
+
+		if distance(prev.Lat, prev.Long, l.Lat, l.Long) < radius {
+			l.Lat, l.Long = prev.Lat, prev.Long
+		}
+		return l, nil
+	})
+}
+
+// kalmanFilter smooth the position with a constant position kalman filter,
+// using the location accuracy as the measurement noise.
+// The state of a bus is removed kalmanStateTTL after its last location.
+type kalmanFilter struct {
+	noise float64 // process noise in meter per second
+	now   func() time.Time
+
+	mu        sync.Mutex
+	states    map[string]kalmanState
+	lastSweep time.Time
+}
+
+type kalmanState struct {
+	lat, long float64
+	variance  float64 // in square meter
+	timestamp time.Time
+	seen      time.Time // server time of the last location
+}
+
+// NewKalmanFilter smooth the jitter of the position, noise is how fast in meter per second
+// the position is expected to change. A lower noise smooth more, but lag more behind a moving bus.
+func NewKalmanFilter(noise float64) Filter {
+	return &kalmanFilter{noise: noise, now: time.Now, states: make(map[string]kalmanState)}
+}
+
+// sweep remove the state of the buses not seen for kalmanStateTTL, at most once per kalmanStateTTL.
+// The caller must hold f.mu.
+func (f *kalmanFilter) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < kalmanStateTTL {
+		return
+	}
+	f.lastSweep = now
+
+	for id, s := range f.states {
+		if now.Sub(s.seen) >= kalmanStateTTL {
+			delete(f.states, id)
+		}
+	}
+}
+
+func (f *kalmanFilter) Filter(_ *Location, l Location) (Location, error) {
+	accuracy := defaultAccuracy
+	if l.Accuracy != nil {
+		accuracy = *l.Accuracy
+	}
+	if accuracy < minAccuracy {
+		accuracy = minAccuracy
+	}
+	measurement := accuracy * accuracy
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.sweep(now)
+
+	s, ok := f.states[l.Bus.ID]
+	if !ok || now.Sub(s.seen) >= kalmanStateTTL {
+		f.states[l.Bus.ID] = kalmanState{lat: l.Lat, long: l.Long, variance: measurement, timestamp: l.Timestamp, seen: now}
+		return l, nil
+	}
+	s.seen = now
+
+	// the uncertainty grow with the time since the previous location.
+	if elapsed := l.Timestamp.Sub(s.timestamp).Seconds(); elapsed > 0 {
+		s.variance += elapsed * f.noise * f.noise
+		s.timestamp = l.Timestamp
+	}
+
+	k := s.variance / (s.variance + measurement)
+	s.lat += k * (l.Lat - s.lat)
+	s.long += k * (l.Long - s.long)
+	s.variance *= 1 - k
+	f.states[l.Bus.ID] = s
+
+	l.Lat, l.Long = s.lat, s.long
+	return l, nil
+}
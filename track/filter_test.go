@@ -0,0 +1,125 @@
+package track
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type senderFunc func(ctx context.Context, l Location) error
+
+func (f senderFunc) Send(ctx context.Context, l Location) error {
+	return f(ctx, l)
+}
+
+func TestFilterChain(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(lat, long float64, sec int) Location {
+		return Location{Lat: lat, Long: long, Bus: Bus{ID: "1"}, Timestamp: ts.Add(time.Duration(sec) * time.Second)}
+	}
+
+	t.Run("max speed", func(t *testing.T) {
+		var rejected []Location
+		tracker := NewTracker(WithHub(HubConfig{}), WithCache(0), WithFilters(NewMaxSpeedFilter(30)),
+			WithDiagnostics(senderFunc(func(_ context.Context, l Location) error {
+				rejected = append(rejected, l)
+				return nil
+			})))
+		before := counter("rejected_max_speed")
+
+		tracker.Receive(at(0, 0, 0))
+		// about 1.1 km in 10 second.
+		tracker.Receive(at(0.01, 0, 10))
+		l, _ := tracker.LastLocation("1")
+		assert.Equal(t, 0.0, l.Lat)
+		assert.Equal(t, []Location{at(0.01, 0, 10)}, rejected)
+		assert.Equal(t, before+1, counter("rejected_max_speed"))
+
+		// the rejected location doesn't become the previous location,
+		// and the same position is plausible later on.
+		tracker.Receive(at(0.001, 0, 20))
+		tracker.Receive(at(0.01, 0, 60))
+		l, _ = tracker.LastLocation("1")
+		assert.Equal(t, 0.01, l.Lat)
+		assert.Len(t, rejected, 1)
+	})
+
+	t.Run("max speed outlier anchor", func(t *testing.T) {
+		chain := newFilterChain([]Filter{NewMaxSpeedFilter(30)})
+
+		// the first location is an outlier about 111 km away from the real position.
+		_, err := chain.apply(at(1, 0, 0))
+		assert.NoError(t, err)
+		for i := 1; i <= maxSpeedRejections; i++ {
+			_, err = chain.apply(at(0, 0, i))
+			assert.Error(t, err)
+		}
+
+		// the bus is accepted again and becomes the previous location.
+		l, err := chain.apply(at(0, 0, maxSpeedRejections+1))
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, l.Lat)
+		_, err = chain.apply(at(0.0001, 0, maxSpeedRejections+2))
+		assert.NoError(t, err)
+	})
+
+	t.Run("stationary", func(t *testing.T) {
+		chain := newFilterChain([]Filter{NewStationaryFilter(10)})
+
+		l, err := chain.apply(at(0, 0, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, 0.0, l.Lat)
+
+		// about 5 meter of drift.
+		l, _ = chain.apply(at(0.00005, 0, 10))
+		assert.Equal(t, 0.0, l.Lat)
+
+		// moving bus is not kept in place.
+		speed := 5.0
+		moving := at(0.00005, 0, 20)
+		moving.Speed = &speed
+		l, _ = chain.apply(moving)
+		assert.Equal(t, 0.00005, l.Lat)
+
+		l, _ = chain.apply(at(0.001, 0, 30))
+		assert.Equal(t, 0.001, l.Lat)
+	})
+
+	t.Run("kalman", func(t *testing.T) {
+		chain := newFilterChain([]Filter{NewKalmanFilter(1)})
+
+		l, _ := chain.apply(at(0, 0, 0))
+		assert.Equal(t, 0.0, l.Lat)
+
+		// a jump within the accuracy is smoothed.
+		l, _ = chain.apply(at(0.0001, 0, 1))
+		assert.InDelta(t, 0.00005, l.Lat, 0.00001)
+
+		// an accurate location is trusted more.
+		accuracy := 1.0
+		accurate := at(0.0001, 0, 2)
+		accurate.Accuracy = &accuracy
+		l, _ = chain.apply(accurate)
+		assert.InDelta(t, 0.0001, l.Lat, 0.00002)
+	})
+
+	t.Run("kalman eviction", func(t *testing.T) {
+		now := ts
+		f := NewKalmanFilter(1).(*kalmanFilter)
+		f.now = func() time.Time { return now }
+
+		f.Filter(nil, at(0, 0, 0))
+		now = now.Add(kalmanStateTTL)
+		other := at(0, 0, 0)
+		other.Bus.ID = "2"
+		f.Filter(nil, other)
+
+		// the bus that stopped reporting is removed, its next location starts a new state.
+		assert.NotContains(t, f.states, "1")
+		assert.Contains(t, f.states, "2")
+		l, _ := f.Filter(nil, at(0.0001, 0, 600))
+		assert.Equal(t, 0.0001, l.Lat)
+	})
+}
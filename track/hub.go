@@ -1,35 +1,476 @@
 package track
 
-import "sync"
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
 
+const (
+	defaultBufferSize = 16
+	defaultShards     = 32
+)
+
+// ErrTooManyConnections returned when a customer already reach the maximum number of connection.
+var ErrTooManyConnections = errors.New("too many connections for customer")
+
+// ErrUnknownHandle returned when the connection is not registered, or already removed from the hub.
+var ErrUnknownHandle = errors.New("unknown handle")
+
+// stats exposes the hub counters through expvar (/debug/vars).
+var stats = expvar.NewMap("track")
+
+// HubConfig denotes how the hub buffer location for each subscriber.
+type HubConfig struct {
+	// BufferSize is the number of location that can be pending for a single subscriber.
+	BufferSize int
+	// Overflow decide what happen when the buffer of a subscriber is full.
+	Overflow OverflowPolicy
+	// MaxConnectionsPerCustomer limit the number of concurrent connection of a single customer.
+	// Zero means unlimited. Anonymous customer (empty ID) is never limited.
+	MaxConnectionsPerCustomer int
+	// Shards is the number of partition of the bus and customer index.
+	// Register and unregister only lock the partitions they touch.
+	Shards int
+}
+
+// hub fan-out location to the subscribers of each bus.
+//
+// Subscribers of a bus are kept in a copy on write slice of that bus only,
+// so receive never take a lock and register/unregister only copy the subscribers of the bus they touch,
+// and only contend with other registration on the same partitions. Connections are indexed separately,
+// partitioned by customer id. Subscribers following a route are indexed the same way by route id,
+// so a bus joining the route can be added to them.
 type hub struct {
-	customers map[string]chan Location
+	busShards      []*busShard
+	routeShards    []*busShard
+	customerShards []*customerShard
+	nextID         atomic.Uint64
+
+	cfg HubConfig
+}
+
+// busShard maps bus id, or route id in the route index, to the *atomic.Pointer[[]*subscriber] of its subscribers.
+// The slice is never mutated once published.
+type busShard struct {
+	mu    sync.Mutex // serialize writers
+	buses sync.Map
+}
+
+type customerShard struct {
 	mu        sync.Mutex
+	customers map[string]map[uint64]*subscriber // customer id -> handle id -> subscriber
+}
+
+// subscriber denotes a registered connection channel and the buses it follows.
+// Update is buffered in the mailbox and delivered to the channel by its own goroutine,
+// so a slow connection only block itself.
+type subscriber struct {
+	handle  Handle
+	ch      chan Update
+	mb      *mailbox
+	removed atomic.Bool
+
+	mu     sync.Mutex // guard buses and routes
+	buses  []string
+	routes []string
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newHub(cfg HubConfig) *hub {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = DropOldest
+	}
+	if cfg.Shards <= 0 {
+		cfg.Shards = defaultShards
+	}
+
+	h := &hub{
+		busShards:      make([]*busShard, cfg.Shards),
+		routeShards:    make([]*busShard, cfg.Shards),
+		customerShards: make([]*customerShard, cfg.Shards),
+		cfg:            cfg,
+	}
+	for i := 0; i < cfg.Shards; i++ {
+		h.busShards[i] = &busShard{}
+		h.routeShards[i] = &busShard{}
+		h.customerShards[i] = &customerShard{customers: make(map[string]map[uint64]*subscriber)}
+	}
+
+	return h
+}
+
+// register add the connection to the hub.
+// When snapshot is not nil, its result is delivered before any live location.
+func (h *hub) register(c Customer, s Subscription, l chan Update, snapshot func(busIDs []string) []Update) (Handle, error) {
+	sub := &subscriber{
+		handle:  Handle{id: h.nextID.Add(1), Customer: c},
+		ch:      l,
+		buses:   s.busIDs(),
+		mb:      newMailbox(h.cfg.BufferSize, h.cfg.Overflow),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	cs := h.customerShard(c.ID)
+	cs.mu.Lock()
+	if max := h.cfg.MaxConnectionsPerCustomer; max > 0 && c.ID != "" && len(cs.customers[c.ID]) >= max {
+		cs.mu.Unlock()
+		return Handle{}, ErrTooManyConnections
+	}
+	if _, ok := cs.customers[c.ID]; !ok {
+		cs.customers[c.ID] = make(map[uint64]*subscriber)
+	}
+	cs.customers[c.ID][sub.handle.id] = sub
+	cs.mu.Unlock()
+
+	for _, busID := range sub.buses {
+		h.busShard(busID).add(busID, sub)
+	}
+
+	// the snapshot is taken after the subscriber is indexed, so any update
+	// missed by the snapshot is already buffered as live update.
+	if snapshot != nil {
+		sub.mb.seed(snapshot(sub.buses))
+	}
+
+	go sub.run()
+	return sub.handle, nil
+}
+
+func (h *hub) unregister(hd Handle) {
+	if sub, ok := h.subscriber(hd); ok {
+		h.remove(sub)
+	}
+}
+
+// subscribe add the buses to a registered connection.
+// When snapshot is not nil, the result for the newly added buses is delivered before their live update.
+func (h *hub) subscribe(hd Handle, busIDs []string, snapshot func(busIDs []string) []Update) error {
+	sub, ok := h.subscriber(hd)
+	if !ok {
+		return ErrUnknownHandle
+	}
+
+	sub.mu.Lock()
+	// remove read the buses after marking the subscriber removed,
+	// so a bus added after that would never be unindexed.
+	if sub.removed.Load() {
+		sub.mu.Unlock()
+		return ErrUnknownHandle
+	}
+
+	current := make(map[string]struct{}, len(sub.buses))
+	for _, id := range sub.buses {
+		current[id] = struct{}{}
+	}
+
+	var added []string
+	for _, id := range (Subscription{BusIDs: busIDs}).busIDs() {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		h.busShard(id).add(id, sub)
+		added = append(added, id)
+	}
+	sub.buses = append(sub.buses, added...)
+	sub.mu.Unlock()
+
+	if snapshot != nil && len(added) > 0 {
+		sub.mb.seed(snapshot(added))
+	}
+	return nil
+}
+
+// unsubscribe remove the buses from a registered connection.
+// Update of the buses that is already buffered is still delivered.
+func (h *hub) unsubscribe(hd Handle, busIDs []string) error {
+	sub, ok := h.subscriber(hd)
+	if !ok {
+		return ErrUnknownHandle
+	}
+
+	remove := make(map[string]struct{}, len(busIDs))
+	for _, id := range busIDs {
+		remove[id] = struct{}{}
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.removed.Load() {
+		return ErrUnknownHandle
+	}
+
+	buses := make([]string, 0, len(sub.buses))
+	for _, id := range sub.buses {
+		if _, ok := remove[id]; ok {
+			h.busShard(id).remove(id, sub)
+			continue
+		}
+		buses = append(buses, id)
+	}
+	sub.buses = buses
+	return nil
+}
+
+// subscribeRoutes add the routes to a registered connection, see routeSubscribers.
+func (h *hub) subscribeRoutes(hd Handle, routeIDs []string) error {
+	sub, ok := h.subscriber(hd)
+	if !ok {
+		return ErrUnknownHandle
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.removed.Load() {
+		return ErrUnknownHandle
+	}
+
+	current := make(map[string]struct{}, len(sub.routes))
+	for _, id := range sub.routes {
+		current[id] = struct{}{}
+	}
+	for _, id := range (Subscription{BusIDs: routeIDs}).busIDs() {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		h.routeShard(id).add(id, sub)
+		sub.routes = append(sub.routes, id)
+	}
+	return nil
 }
 
-func (h *hub) register(c Customer, l chan Location) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+// unsubscribeRoutes remove the routes from a registered connection,
+// the buses already added for the routes are kept.
+func (h *hub) unsubscribeRoutes(hd Handle, routeIDs []string) error {
+	sub, ok := h.subscriber(hd)
+	if !ok {
+		return ErrUnknownHandle
+	}
+
+	remove := make(map[string]struct{}, len(routeIDs))
+	for _, id := range routeIDs {
+		remove[id] = struct{}{}
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.removed.Load() {
+		return ErrUnknownHandle
+	}
+
+	routes := make([]string, 0, len(sub.routes))
+	for _, id := range sub.routes {
+		if _, ok := remove[id]; ok {
+			h.routeShard(id).remove(id, sub)
+			continue
+		}
+		routes = append(routes, id)
+	}
+	sub.routes = routes
+	return nil
+}
 
-	h.customers[c.ID] = l
+// routeSubscribers returns the handle of the connections following the route and not yet the bus.
+func (h *hub) routeSubscribers(routeID, busID string) []Handle {
+	var handles []Handle
+	for _, sub := range h.routeShard(routeID).subscribers(routeID) {
+		if !sub.removed.Load() && !sub.follows(busID) {
+			handles = append(handles, sub.handle)
+		}
+	}
+	return handles
 }
 
-func (h *hub) unregister(c Customer) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+func (h *hub) subscriber(hd Handle) (*subscriber, bool) {
+	cs := h.customerShard(hd.Customer.ID)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	sub, ok := cs.customers[hd.Customer.ID][hd.id]
+	return sub, ok
+}
 
-	if ch, ok := h.customers[c.ID]; ok {
-		close(ch)
+// remove unindex the subscriber, stop it and close its channel.
+// It is safe to call it more than once.
+func (h *hub) remove(sub *subscriber) {
+	if !sub.removed.CompareAndSwap(false, true) {
+		return
 	}
 
-	delete(h.customers, c.ID)
+	id, customerID := sub.handle.id, sub.handle.Customer.ID
+	cs := h.customerShard(customerID)
+	cs.mu.Lock()
+	delete(cs.customers[customerID], id)
+	if len(cs.customers[customerID]) == 0 {
+		delete(cs.customers, customerID)
+	}
+	cs.mu.Unlock()
+
+	sub.mu.Lock()
+	for _, busID := range sub.buses {
+		h.busShard(busID).remove(busID, sub)
+	}
+	for _, routeID := range sub.routes {
+		h.routeShard(routeID).remove(routeID, sub)
+	}
+	sub.mu.Unlock()
+
+	sub.stop()
+	close(sub.ch)
 }
 
-func (h *hub) receive(l Location) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
+// receive buffer the update for every subscriber of the bus.
+// It never wait for the subscriber to read the update.
+func (h *hub) receive(u Update) {
+	for _, sub := range h.busShard(u.BusID()).subscribers(u.BusID()) {
+		if sub.mb.push(u) {
+			continue
+		}
 
-	for _, v := range h.customers {
-		v <- l
+		stats.Add("dropped", 1)
+		if h.cfg.Overflow == Disconnect && !sub.removed.Load() {
+			stats.Add("evicted", 1)
+			h.remove(sub)
+		}
 	}
 }
+
+// dropped returns the number of location dropped for every connection of the customer.
+func (h *hub) dropped(c Customer) uint64 {
+	cs := h.customerShard(c.ID)
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	var n uint64
+	for _, sub := range cs.customers[c.ID] {
+		n += sub.mb.droppedCount()
+	}
+	return n
+}
+
+func (h *hub) busShard(busID string) *busShard {
+	return h.busShards[shardOf(busID, len(h.busShards))]
+}
+
+func (h *hub) routeShard(routeID string) *busShard {
+	return h.routeShards[shardOf(routeID, len(h.routeShards))]
+}
+
+func (h *hub) customerShard(customerID string) *customerShard {
+	return h.customerShards[shardOf(customerID, len(h.customerShards))]
+}
+
+// subscribers returns the subscribers of the bus.
+func (s *busShard) subscribers(busID string) []*subscriber {
+	v, ok := s.buses.Load(busID)
+	if !ok {
+		return nil
+	}
+	return *v.(*atomic.Pointer[[]*subscriber]).Load()
+}
+
+// add publish a copy of the subscribers of the bus with the subscriber appended.
+func (s *busShard) add(busID string, sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.subscribers(busID)
+	subs := make([]*subscriber, 0, len(old)+1)
+	subs = append(subs, old...)
+	subs = append(subs, sub)
+
+	if v, ok := s.buses.Load(busID); ok {
+		v.(*atomic.Pointer[[]*subscriber]).Store(&subs)
+		return
+	}
+	// the pointer is set before it is stored, receive never see it empty.
+	var p atomic.Pointer[[]*subscriber]
+	p.Store(&subs)
+	s.buses.Store(busID, &p)
+}
+
+// remove publish a copy of the subscribers of the bus without the subscriber,
+// the bus is forgotten once it has no subscriber.
+func (s *busShard) remove(busID string, sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.subscribers(busID)
+	subs := make([]*subscriber, 0, len(old))
+	for _, v := range old {
+		if v != sub {
+			subs = append(subs, v)
+		}
+	}
+
+	if len(subs) == 0 {
+		s.buses.Delete(busID)
+		return
+	}
+	if v, ok := s.buses.Load(busID); ok {
+		v.(*atomic.Pointer[[]*subscriber]).Store(&subs)
+	}
+}
+
+// shardOf hash the key with FNV-1a.
+func shardOf(key string, n int) int {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return int(h % uint32(n))
+}
+
+// follows report whether the subscriber follows the bus.
+func (s *subscriber) follows(busID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.buses {
+		if id == busID {
+			return true
+		}
+	}
+	return false
+}
+
+// run deliver the buffered update to the subscriber channel until it is stopped.
+func (s *subscriber) run() {
+	defer close(s.stopped)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.mb.notify:
+		}
+
+		for {
+			u, ok := s.mb.pop()
+			if !ok {
+				break
+			}
+
+			select {
+			case s.ch <- u:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// stop the delivery goroutine and wait for it to exit,
+// after that it is safe to close the channel.
+func (s *subscriber) stop() {
+	close(s.done)
+	<-s.stopped
+}
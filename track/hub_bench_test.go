@@ -0,0 +1,255 @@
+package track
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fanout is the behaviour shared by the hub and the single lock baseline.
+type fanout interface {
+	register(c Customer, s Subscription, l chan Update, snapshot func([]string) []Update) (Handle, error)
+	unregister(hd Handle)
+	receive(u Update)
+}
+
+// mutexHub is the previous hub design kept as benchmark baseline:
+// a single lock guarding every index, held during the whole fan-out.
+type mutexHub struct {
+	mu          sync.Mutex
+	subscribers map[uint64]*subscriber
+	buses       map[string]map[uint64]*subscriber
+	nextID      uint64
+	cfg         HubConfig
+}
+
+func newMutexHub(cfg HubConfig) *mutexHub {
+	return &mutexHub{
+		subscribers: make(map[uint64]*subscriber),
+		buses:       make(map[string]map[uint64]*subscriber),
+		cfg:         newHub(cfg).cfg,
+	}
+}
+
+func (h *mutexHub) register(c Customer, s Subscription, l chan Update, _ func([]string) []Update) (Handle, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	sub := &subscriber{
+		handle:  Handle{id: h.nextID, Customer: c},
+		ch:      l,
+		buses:   s.busIDs(),
+		mb:      newMailbox(h.cfg.BufferSize, h.cfg.Overflow),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	h.subscribers[sub.handle.id] = sub
+	for _, busID := range sub.buses {
+		if _, ok := h.buses[busID]; !ok {
+			h.buses[busID] = make(map[uint64]*subscriber)
+		}
+		h.buses[busID][sub.handle.id] = sub
+	}
+
+	go sub.run()
+	return sub.handle, nil
+}
+
+func (h *mutexHub) unregister(hd Handle) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub, ok := h.subscribers[hd.id]
+	if !ok {
+		return
+	}
+	delete(h.subscribers, hd.id)
+	for _, busID := range sub.buses {
+		delete(h.buses[busID], hd.id)
+	}
+	sub.stop()
+	close(sub.ch)
+}
+
+func (h *mutexHub) receive(u Update) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range h.buses[u.BusID()] {
+		sub.mb.push(u)
+	}
+}
+
+var benchHubs = []struct {
+	name string
+	new  func() fanout
+}{
+	{name: "mutex", new: func() fanout { return newMutexHub(HubConfig{}) }},
+	{name: "sharded", new: func() fanout { return newHub(HubConfig{}) }},
+}
+
+const (
+	benchBuses             = 200
+	benchSubscribersPerBus = 50
+)
+
+// setupFanout register benchBuses*benchSubscribersPerBus readers.
+// The first reader of every bus record the fan-out latency of each location it gets.
+func setupFanout(b *testing.B, h fanout) (latencies func() []time.Duration, stop func()) {
+	var (
+		mu      sync.Mutex
+		samples []time.Duration
+		wg      sync.WaitGroup
+		handles []Handle
+	)
+
+	for bus := 0; bus < benchBuses; bus++ {
+		for i := 0; i < benchSubscribersPerBus; i++ {
+			l := make(chan Update)
+			hd, err := h.register(Customer{ID: fmt.Sprintf("%d-%d", bus, i)}, Subscription{BusIDs: []string{fmt.Sprint(bus)}}, l, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			handles = append(handles, hd)
+
+			wg.Add(1)
+			go func(record bool) {
+				defer wg.Done()
+				for u := range l {
+					if record {
+						d := time.Since(u.Location.Timestamp)
+						mu.Lock()
+						samples = append(samples, d)
+						mu.Unlock()
+					}
+				}
+			}(i == 0)
+		}
+	}
+
+	latencies = func() []time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Duration(nil), samples...)
+	}
+	stop = func() {
+		for _, hd := range handles {
+			h.unregister(hd)
+		}
+		wg.Wait()
+	}
+	return latencies, stop
+}
+
+func reportP99(b *testing.B, samples []time.Duration) {
+	if len(samples) == 0 {
+		return
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	b.ReportMetric(float64(samples[len(samples)*99/100].Nanoseconds()), "p99-ns")
+}
+
+// benchInterval is the pace of the locations sent while the latency is measured,
+// well under the fan-out capacity so the latency is not the queueing of a saturated hub.
+const benchInterval = time.Millisecond
+
+// pace send a location of the next bus every benchInterval until done is closed.
+func pace(h fanout, done <-chan struct{}) {
+	ticker := time.NewTicker(benchInterval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			h.receive(update(Location{Bus: Bus{ID: fmt.Sprint(i % benchBuses)}, Timestamp: time.Now()}))
+		}
+	}
+}
+
+// BenchmarkHubReceive measure fan-out throughput
+// with locations of every bus received concurrently.
+func BenchmarkHubReceive(b *testing.B) {
+	for _, bh := range benchHubs {
+		b.Run(bh.name, func(b *testing.B) {
+			h := bh.new()
+			_, stop := setupFanout(b, h)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					h.receive(update(Location{Bus: Bus{ID: fmt.Sprint(i % benchBuses)}, Timestamp: time.Now()}))
+					i++
+				}
+			})
+			b.StopTimer()
+
+			stop()
+		})
+	}
+}
+
+// BenchmarkHubLatency measure fan-out latency of locations sent every benchInterval.
+func BenchmarkHubLatency(b *testing.B) {
+	for _, bh := range benchHubs {
+		b.Run(bh.name, func(b *testing.B) {
+			h := bh.new()
+			latencies, stop := setupFanout(b, h)
+
+			ticker := time.NewTicker(benchInterval)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				<-ticker.C
+				h.receive(update(Location{Bus: Bus{ID: fmt.Sprint(i % benchBuses)}, Timestamp: time.Now()}))
+			}
+			b.StopTimer()
+			ticker.Stop()
+
+			reportP99(b, latencies())
+			stop()
+		})
+	}
+}
+
+// BenchmarkHubRegister measure connection churn, and the latency of locations sent every benchInterval meanwhile.
+func BenchmarkHubRegister(b *testing.B) {
+	for _, bh := range benchHubs {
+		b.Run(bh.name, func(b *testing.B) {
+			h := bh.new()
+			latencies, stop := setupFanout(b, h)
+
+			done := make(chan struct{})
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pace(h, done)
+			}()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					hd, err := h.register(Customer{ID: fmt.Sprint(i)}, Subscription{BusIDs: []string{fmt.Sprint(i % benchBuses)}}, make(chan Update), nil)
+					if err != nil {
+						b.Error(err)
+						return
+					}
+					h.unregister(hd)
+					i++
+				}
+			})
+			b.StopTimer()
+
+			close(done)
+			wg.Wait()
+			reportP99(b, latencies())
+			stop()
+		})
+	}
+}
@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTracking(t *testing.T) {
-	h := hub{
-		customers: make(map[string]chan Location),
-	}
+	h := newHub(HubConfig{})
 
 	var wg sync.WaitGroup
 	var wgRegister sync.WaitGroup
@@ -24,18 +23,19 @@ func TestTracking(t *testing.T) {
 	for i := 0; i < 3; i++ {
 		go func(id string) {
 			c := Customer{ID: id}
-			l := make(chan Location)
-			h.register(c, l)
+			l := make(chan Update)
+			hd, err := h.register(c, Subscription{BusIDs: []string{"1"}}, l, nil)
+			assert.NoError(t, err)
 			wgRegister.Done()
 
 			for j := 0; j < 3; j++ {
-				loc := <-l
+				u := <-l
 				mu.Lock()
-				msgReceived[id] = append(msgReceived[id], loc)
+				msgReceived[id] = append(msgReceived[id], *u.Location)
 				mu.Unlock()
 			}
 
-			h.unregister(c)
+			h.unregister(hd)
 			wg.Done()
 		}(fmt.Sprint(i))
 	}
@@ -48,8 +48,9 @@ func TestTracking(t *testing.T) {
 			loc := Location{
 				Long: float64(i),
 				Lat:  float64(i),
+				Bus:  Bus{ID: "1"},
 			}
-			h.receive(loc)
+			h.receive(update(loc))
 		}
 		wg.Done()
 	}()
@@ -60,46 +61,336 @@ func TestTracking(t *testing.T) {
 			Location{
 				Long: 0,
 				Lat:  0,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 1,
 				Lat:  1,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 2,
 				Lat:  2,
+				Bus:  Bus{ID: "1"},
 			},
 		},
 		"1": {
 			Location{
 				Long: 0,
 				Lat:  0,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 1,
 				Lat:  1,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 2,
 				Lat:  2,
+				Bus:  Bus{ID: "1"},
 			},
 		},
 		"2": {
 			Location{
 				Long: 0,
 				Lat:  0,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 1,
 				Lat:  1,
+				Bus:  Bus{ID: "1"},
 			},
 			Location{
 				Long: 2,
 				Lat:  2,
+				Bus:  Bus{ID: "1"},
 			},
 		},
 	}
 
 	assert.EqualValues(t, expected, msgReceived)
-	assert.Empty(t, h.customers)
+	assertEmptyHub(t, h)
+}
+
+func TestTrackingSubscription(t *testing.T) {
+	h := newHub(HubConfig{})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	msgReceived := make(map[string][]string)
+
+	// customer 0 follow bus 1, customer 1 follow bus 2, customer 2 follow both.
+	subs := map[string][]string{
+		"0": {"1"},
+		"1": {"2"},
+		"2": {"1", "2"},
+	}
+	expectedCount := map[string]int{"0": 1, "1": 2, "2": 3}
+	for id, busIDs := range subs {
+		c := Customer{ID: id}
+		l := make(chan Update)
+		hd, err := h.register(c, Subscription{BusIDs: busIDs}, l, nil)
+		assert.NoError(t, err)
+
+		wg.Add(1)
+		go func(hd Handle, c Customer, l chan Update, n int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				loc := (<-l).Location
+				mu.Lock()
+				msgReceived[c.ID] = append(msgReceived[c.ID], loc.Bus.ID)
+				mu.Unlock()
+			}
+			h.unregister(hd)
+		}(hd, c, l, expectedCount[id])
+	}
+
+	// location of bus 3 has no subscriber and must not block.
+	for _, busID := range []string{"1", "2", "3", "2"} {
+		h.receive(update(Location{Bus: Bus{ID: busID}}))
+	}
+
+	wg.Wait()
+	expected := map[string][]string{
+		"0": {"1"},
+		"1": {"2", "2"},
+		"2": {"1", "2", "2"},
+	}
+
+	assert.EqualValues(t, expected, msgReceived)
+	assertEmptyHub(t, h)
+}
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	h := newHub(HubConfig{})
+	l := make(chan Update)
+	hd, err := h.register(Customer{ID: "1"}, Subscription{BusIDs: []string{"1"}}, l, nil)
+	assert.NoError(t, err)
+
+	// the snapshot is only taken for the newly added bus.
+	assert.NoError(t, h.subscribe(hd, []string{"1", "2"}, func(busIDs []string) []Update {
+		assert.Equal(t, []string{"2"}, busIDs)
+		return []Update{update(Location{Lat: 1, Bus: Bus{ID: "2"}})}
+	}))
+	assert.Equal(t, float64(1), (<-l).Location.Lat)
+
+	h.receive(update(Location{Lat: 2, Bus: Bus{ID: "2"}}))
+	assert.Equal(t, float64(2), (<-l).Location.Lat)
+
+	assert.NoError(t, h.unsubscribe(hd, []string{"1"}))
+	h.receive(update(Location{Lat: 3, Bus: Bus{ID: "1"}}))
+	h.receive(update(Location{Lat: 4, Bus: Bus{ID: "2"}}))
+	assert.Equal(t, float64(4), (<-l).Location.Lat)
+
+	h.unregister(hd)
+	assert.ErrorIs(t, h.subscribe(hd, []string{"3"}, nil), ErrUnknownHandle)
+	assert.ErrorIs(t, h.unsubscribe(hd, []string{"2"}), ErrUnknownHandle)
+	assertEmptyHub(t, h)
+}
+
+func TestSlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	h := newHub(HubConfig{BufferSize: 4, Overflow: DropOldest})
+
+	// slow customer never read its channel.
+	slow := Customer{ID: "slow"}
+	slowHandle, _ := h.register(slow, Subscription{BusIDs: []string{"1"}}, make(chan Update), nil)
+
+	fast := Customer{ID: "fast"}
+	fastChan := make(chan Update)
+	fastHandle, _ := h.register(fast, Subscription{BusIDs: []string{"1"}}, fastChan, nil)
+
+	// the fast customer must get every location even though the slow one stopped reading.
+	for i := 0; i < 100; i++ {
+		done := make(chan struct{})
+		go func() {
+			h.receive(update(Location{Lat: float64(i), Bus: Bus{ID: "1"}}))
+			close(done)
+		}()
+
+		select {
+		case u := <-fastChan:
+			assert.Equal(t, float64(i), u.Location.Lat)
+		case <-time.After(5 * time.Second):
+			t.Fatal("fast subscriber is stalled by the slow one")
+		}
+		<-done
+	}
+
+	assert.Zero(t, h.dropped(fast))
+	assert.Greater(t, h.dropped(slow), uint64(0))
+
+	h.unregister(slowHandle)
+	h.unregister(fastHandle)
+	assertEmptyHub(t, h)
+}
+
+func TestDisconnectPolicyEvictSlowSubscriber(t *testing.T) {
+	h := newHub(HubConfig{BufferSize: 2, Overflow: Disconnect})
+
+	slow := Customer{ID: "slow"}
+	slowChan := make(chan Update)
+	slowHandle, _ := h.register(slow, Subscription{BusIDs: []string{"1"}}, slowChan, nil)
+
+	// the delivery goroutine hold one location and the mailbox the rest.
+	for i := 0; i < 10; i++ {
+		h.receive(update(Location{Bus: Bus{ID: "1"}}))
+	}
+
+	assertEmptyHub(t, h)
+
+	_, ok := <-slowChan
+	assert.False(t, ok)
+
+	// unregister an evicted connection is a no-op.
+	h.unregister(slowHandle)
+}
+
+func TestMultipleConnectionsPerCustomer(t *testing.T) {
+	h := newHub(HubConfig{MaxConnectionsPerCustomer: 2})
+	c := Customer{ID: "1"}
+
+	first, second := make(chan Update), make(chan Update)
+	firstHandle, err := h.register(c, Subscription{BusIDs: []string{"1"}}, first, nil)
+	assert.NoError(t, err)
+	secondHandle, err := h.register(c, Subscription{BusIDs: []string{"1"}}, second, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, firstHandle, secondHandle)
+
+	// the third connection exceed the limit.
+	_, err = h.register(c, Subscription{BusIDs: []string{"1"}}, make(chan Update), nil)
+	assert.ErrorIs(t, err, ErrTooManyConnections)
+
+	// both connection receive the location.
+	h.receive(update(Location{Bus: Bus{ID: "1"}}))
+	assert.Equal(t, "1", (<-first).Location.Bus.ID)
+	assert.Equal(t, "1", (<-second).Location.Bus.ID)
+
+	// closing the first connection must not affect the second one.
+	h.unregister(firstHandle)
+	_, ok := <-first
+	assert.False(t, ok)
+
+	h.receive(update(Location{Bus: Bus{ID: "1"}}))
+	assert.Equal(t, "1", (<-second).Location.Bus.ID)
+
+	// freed slot can be used again.
+	thirdHandle, err := h.register(c, Subscription{BusIDs: []string{"1"}}, make(chan Update), nil)
+	assert.NoError(t, err)
+
+	// anonymous customers are not limited.
+	for i := 0; i < 3; i++ {
+		hd, err := h.register(Customer{}, Subscription{BusIDs: []string{"1"}}, make(chan Update), nil)
+		assert.NoError(t, err)
+		h.unregister(hd)
+	}
+
+	h.unregister(secondHandle)
+	h.unregister(thirdHandle)
+	assertEmptyHub(t, h)
+}
+
+func TestMailboxOverflow(t *testing.T) {
+	locs := []Location{
+		{Lat: 1, Bus: Bus{ID: "1"}},
+		{Lat: 2, Bus: Bus{ID: "2"}},
+		{Lat: 3, Bus: Bus{ID: "1"}},
+		{Lat: 4, Bus: Bus{ID: "3"}},
+	}
+
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []float64
+		dropped  uint64
+	}{
+		{policy: DropOldest, expected: []float64{3, 4}, dropped: 2},
+		{policy: DropNewest, expected: []float64{1, 2}, dropped: 2},
+		{policy: Coalesce, expected: []float64{2, 4}, dropped: 2},
+		{policy: Disconnect, expected: []float64{1, 2}, dropped: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			mb := newMailbox(2, tt.policy)
+			for _, l := range locs {
+				mb.push(update(l))
+			}
+
+			var got []float64
+			for {
+				u, ok := mb.pop()
+				if !ok {
+					break
+				}
+				got = append(got, u.Location.Lat)
+			}
+
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.dropped, mb.droppedCount())
+		})
+	}
+}
+
+func TestMailboxSeed(t *testing.T) {
+	mb := newMailbox(2, DropOldest)
+	mb.push(update(Location{Lat: 3, Bus: Bus{ID: "1"}}))
+
+	// bus 1 already has a live location buffered, so its snapshot is skipped.
+	mb.seed([]Update{update(Location{Lat: 1, Bus: Bus{ID: "1"}}), update(Location{Lat: 2, Bus: Bus{ID: "2"}})})
+
+	var got []float64
+	for {
+		u, ok := mb.pop()
+		if !ok {
+			break
+		}
+		got = append(got, u.Location.Lat)
+	}
+	assert.Equal(t, []float64{2, 3}, got)
+}
+
+func TestMailboxSeedTrip(t *testing.T) {
+	mb := newMailbox(2, DropOldest)
+	mb.push(update(Location{Lat: 2, Bus: Bus{ID: "1"}}))
+
+	// the trip in progress is still delivered, only the older location is skipped.
+	trip := Trip{ID: "t1", Bus: Bus{ID: "1"}, Status: TripStarted}
+	mb.seed([]Update{{Trip: &trip}, update(Location{Lat: 1, Bus: Bus{ID: "1"}})})
+
+	u, ok := mb.pop()
+	assert.True(t, ok)
+	assert.Equal(t, &trip, u.Trip)
+	u, ok = mb.pop()
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), u.Location.Lat)
+	_, ok = mb.pop()
+	assert.False(t, ok)
+}
+
+// assertEmptyHub asserts that no connection is left in any shard.
+func assertEmptyHub(t *testing.T, h *hub) {
+	t.Helper()
+
+	for _, s := range h.busShards {
+		s.buses.Range(func(busID, _ any) bool {
+			t.Errorf("bus %v still has subscribers", busID)
+			return true
+		})
+	}
+	for _, s := range h.routeShards {
+		s.buses.Range(func(routeID, _ any) bool {
+			t.Errorf("route %v still has subscribers", routeID)
+			return true
+		})
+	}
+	for _, s := range h.customerShards {
+		s.mu.Lock()
+		assert.Empty(t, s.customers)
+		s.mu.Unlock()
+	}
+}
+
+func update(l Location) Update {
+	return Update{Location: &l}
 }
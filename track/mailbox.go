@@ -0,0 +1,157 @@
+package track
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OverflowPolicy decide what happen when a subscriber buffer is full.
+type OverflowPolicy string
+
+const (
+	// DropOldest discard the oldest buffered location to make room for the new one.
+	DropOldest OverflowPolicy = "drop_oldest"
+	// DropNewest discard the incoming location and keep the buffered one.
+	DropNewest OverflowPolicy = "drop_newest"
+	// Coalesce replace the buffered location of the same bus with the incoming one,
+	// falling back to DropOldest when there is no buffered location for that bus.
+	Coalesce OverflowPolicy = "coalesce"
+	// Disconnect evict the subscriber from the hub.
+	Disconnect OverflowPolicy = "disconnect"
+)
+
+// ParseOverflowPolicy parse the policy from its config value.
+// Empty value will be defaulted to DropOldest.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case "":
+		return DropOldest, nil
+	case DropOldest, DropNewest, Coalesce, Disconnect:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy %q", s)
+	}
+}
+
+// mailbox is a bounded buffer of update waiting to be delivered to a subscriber.
+// push never block, so a slow subscriber can't stall the hub.
+type mailbox struct {
+	mu      sync.Mutex
+	buf     []Update
+	size    int
+	policy  OverflowPolicy
+	dropped uint64
+
+	// notify is signaled whenever an update is pushed.
+	notify chan struct{}
+}
+
+func newMailbox(size int, policy OverflowPolicy) *mailbox {
+	return &mailbox{
+		buf:    make([]Update, 0, size),
+		size:   size,
+		policy: policy,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// push enqueue the update according to the overflow policy.
+// It returns false when an update has been dropped because the mailbox is full.
+func (m *mailbox) push(u Update) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.buf) < m.size {
+		m.buf = append(m.buf, u)
+		m.signal()
+		return true
+	}
+
+	m.dropped++
+	switch m.policy {
+	case DropNewest, Disconnect:
+		return false
+	case Coalesce:
+		// only a location replace a location, trip event is never coalesced.
+		for i := range m.buf {
+			if u.Location != nil && m.buf[i].Location != nil && m.buf[i].BusID() == u.BusID() {
+				m.buf[i] = u
+				m.signal()
+				return false
+			}
+		}
+	}
+
+	// drop the oldest one
+	copy(m.buf, m.buf[1:])
+	m.buf[len(m.buf)-1] = u
+	m.signal()
+	return false
+}
+
+// seed put the updates in front of the buffer regardless of the buffer size.
+// It is used to deliver the initial snapshot before any live update,
+// an update is skipped when a live update of the same kind is already buffered for its bus, since the live one is newer.
+func (m *mailbox) seed(updates []Update) {
+	if len(updates) == 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live := make(map[updateKey]struct{}, len(m.buf))
+	for _, u := range m.buf {
+		live[u.key()] = struct{}{}
+	}
+
+	buf := make([]Update, 0, len(updates)+len(m.buf))
+	for _, u := range updates {
+		if _, ok := live[u.key()]; !ok {
+			buf = append(buf, u)
+		}
+	}
+	m.buf = append(buf, m.buf...)
+	m.signal()
+}
+
+// updateKey identifies the kind of update of a bus.
+type updateKey struct {
+	busID string
+	trip  bool
+}
+
+func (u Update) key() updateKey {
+	return updateKey{busID: u.BusID(), trip: u.Trip != nil}
+}
+
+// pop dequeue the oldest update.
+func (m *mailbox) pop() (Update, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.buf) == 0 {
+		return Update{}, false
+	}
+
+	u := m.buf[0]
+	copy(m.buf, m.buf[1:])
+	m.buf = m.buf[:len(m.buf)-1]
+	return u, true
+}
+
+func (m *mailbox) droppedCount() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.dropped
+}
+
+// signal the consumer without blocking.
+// The caller must hold m.mu.
+func (m *mailbox) signal() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
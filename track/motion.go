@@ -0,0 +1,58 @@
+package track
+
+import "math"
+
+const (
+	earthRadius = 6371008.8 // mean earth radius in meter
+
+	// minDeriveDistance is the distance under which the bearing is not derived,
+	// since the GPS noise of a stopped bus would make it point anywhere.
+	minDeriveDistance = 1.0
+)
+
+// derive fill the missing speed and bearing of l from the previous location of the same bus.
+// Nothing is derived when l is not newer than prev.
+func derive(prev, l Location) Location {
+	if l.Speed != nil && l.Bearing != nil {
+		return l
+	}
+
+	elapsed := l.Timestamp.Sub(prev.Timestamp).Seconds()
+	if elapsed <= 0 {
+		return l
+	}
+
+	dist := distance(prev.Lat, prev.Long, l.Lat, l.Long)
+	if l.Speed == nil {
+		speed := dist / elapsed
+		l.Speed = &speed
+	}
+	if l.Bearing == nil && dist >= minDeriveDistance {
+		bearing := bearing(prev.Lat, prev.Long, l.Lat, l.Long)
+		l.Bearing = &bearing
+	}
+	return l
+}
+
+// distance returns the great circle distance in meter between two coordinate with the haversine formula.
+func distance(lat1, long1, lat2, long2 float64) float64 {
+	phi1, phi2 := radian(lat1), radian(lat2)
+	dPhi, dLambda := radian(lat2-lat1), radian(long2-long1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// bearing returns the initial bearing in degree between 0 and 360 to travel from the first to the second coordinate.
+func bearing(lat1, long1, lat2, long2 float64) float64 {
+	phi1, phi2 := radian(lat1), radian(lat2)
+	dLambda := radian(long2 - long1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func radian(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,43 @@
+package track
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReceiveDeriveMotion(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(0))
+
+	// first location of the bus has nothing to derive from.
+	tracker.Receive(Location{Lat: 0, Long: 0, Bus: Bus{ID: "1"}, Timestamp: ts})
+	l, ok := tracker.LastLocation("1")
+	assert.True(t, ok)
+	assert.Nil(t, l.Speed)
+	assert.Nil(t, l.Bearing)
+
+	// 0.001 degree north is about 111 meter.
+	tracker.Receive(Location{Lat: 0.001, Long: 0, Bus: Bus{ID: "1"}, Timestamp: ts.Add(10 * time.Second)})
+	l, _ = tracker.LastLocation("1")
+	assert.InDelta(t, 11.1, *l.Speed, 0.1)
+	assert.InDelta(t, 0, *l.Bearing, 0.001)
+
+	// the value sent by the device is kept.
+	speed := 3.0
+	tracker.Receive(Location{Lat: 0.001, Long: 0.001, Bus: Bus{ID: "1"}, Timestamp: ts.Add(20 * time.Second), Speed: &speed})
+	l, _ = tracker.LastLocation("1")
+	assert.Equal(t, 3.0, *l.Speed)
+	assert.InDelta(t, 90, *l.Bearing, 0.001)
+
+	// stationary bus has no bearing, and out of order location derive nothing.
+	tracker.Receive(Location{Lat: 0.001, Long: 0.001, Bus: Bus{ID: "1"}, Timestamp: ts.Add(30 * time.Second)})
+	l, _ = tracker.LastLocation("1")
+	assert.Equal(t, 0.0, *l.Speed)
+	assert.Nil(t, l.Bearing)
+
+	tracker.Receive(Location{Lat: 0, Long: 0, Bus: Bus{ID: "1"}, Timestamp: ts})
+	l, _ = tracker.LastLocation("1")
+	assert.Nil(t, l.Speed)
+}
@@ -0,0 +1,62 @@
+package track
+
+import (
+	"sync"
+	"time"
+)
+
+// orderer keep the newest location received for every bus, to drop the location
+// replayed by a retrying driver or a broker redelivery, and the one arriving late.
+type orderer struct {
+	shards []*orderShard
+}
+
+type orderShard struct {
+	mu     sync.Mutex
+	newest map[string]orderMark
+}
+
+type orderMark struct {
+	timestamp time.Time
+	seq       uint64
+}
+
+func newOrderer() *orderer {
+	o := &orderer{shards: make([]*orderShard, defaultShards)}
+	for i := range o.shards {
+		o.shards[i] = &orderShard{newest: make(map[string]orderMark)}
+	}
+	return o
+}
+
+// accept report whether l is newer than every location received for its bus, and record it when it is.
+//
+// When both location carry a sequence number, it decides the order. A lower sequence number
+// with a newer timestamp is accepted since the driver app restarted its sequence.
+// Otherwise, the timestamp decides.
+func (o *orderer) accept(l Location) bool {
+	s := o.shards[shardOf(l.Bus.ID, len(o.shards))]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, ok := s.newest[l.Bus.ID]
+	if ok {
+		switch {
+		case l.Seq != 0 && last.seq != 0 && l.Seq == last.seq:
+			stats.Add("duplicated", 1)
+			return false
+		case l.Seq != 0 && last.seq != 0 && l.Seq < last.seq && !l.Timestamp.After(last.timestamp):
+			stats.Add("reordered", 1)
+			return false
+		case (l.Seq == 0 || last.seq == 0) && l.Timestamp.Equal(last.timestamp):
+			stats.Add("duplicated", 1)
+			return false
+		case (l.Seq == 0 || last.seq == 0) && l.Timestamp.Before(last.timestamp):
+			stats.Add("reordered", 1)
+			return false
+		}
+	}
+
+	s.newest[l.Bus.ID] = orderMark{timestamp: l.Timestamp, seq: l.Seq}
+	return true
+}
@@ -0,0 +1,75 @@
+package track
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrdering(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(sec int, seq uint64) Location {
+		return Location{Bus: Bus{ID: "1"}, Timestamp: ts.Add(time.Duration(sec) * time.Second), Seq: seq}
+	}
+
+	tests := []struct {
+		name     string
+		received []Location
+		accepted []bool
+	}{
+		{
+			name:     "timestamp",
+			received: []Location{at(1, 0), at(1, 0), at(0, 0), at(2, 0)},
+			accepted: []bool{true, false, false, true},
+		},
+		{
+			name:     "sequence",
+			received: []Location{at(1, 1), at(2, 1), at(2, 3), at(3, 2), at(3, 4)},
+			accepted: []bool{true, false, true, true, true},
+		},
+		{
+			name:     "reordered sequence",
+			received: []Location{at(1, 5), at(0, 4)},
+			accepted: []bool{true, false},
+		},
+		{
+			name:     "sequence restarted",
+			received: []Location{at(1, 5), at(2, 1), at(3, 2)},
+			accepted: []bool{true, true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrderer()
+			for i, l := range tt.received {
+				assert.Equal(t, tt.accepted[i], o.accept(l), "location %d", i)
+			}
+		})
+	}
+}
+
+func TestReceiveDropStale(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(0), WithOrdering())
+	reordered, duplicated := counter("reordered"), counter("duplicated")
+
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "1"}, Timestamp: ts.Add(time.Second)})
+	tracker.Receive(Location{Lat: 2, Bus: Bus{ID: "1"}, Timestamp: ts})
+	tracker.Receive(Location{Lat: 3, Bus: Bus{ID: "1"}, Timestamp: ts.Add(time.Second)})
+
+	l, _ := tracker.LastLocation("1")
+	assert.Equal(t, 1.0, l.Lat)
+	assert.Equal(t, reordered+1, counter("reordered"))
+	assert.Equal(t, duplicated+1, counter("duplicated"))
+}
+
+// counter returns the current value of the track expvar counter.
+func counter(name string) int64 {
+	if v, ok := stats.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
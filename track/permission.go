@@ -0,0 +1,155 @@
+package track
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrNotAllowed returned when the customer is not allowed to follow the bus.
+var ErrNotAllowed = errors.New("bus not allowed for customer")
+
+// Permission denotes the buses a customer is allowed to follow,
+// either directly or because the bus is serving one of the routes.
+type Permission struct {
+	BusIDs   []string
+	RouteIDs []string
+}
+
+// RouteLookup find the route a bus is currently serving.
+type RouteLookup interface {
+	RouteOf(busID string) (routeID string, ok bool)
+}
+
+// BusLister is implemented by a RouteLookup knowing every bus it can find the route of,
+// so the buses of a route can be found before they send their location.
+type BusLister interface {
+	BusIDs() []string
+}
+
+// BusRoutes is the route every bus is assigned to, by bus id.
+type BusRoutes map[string]string
+
+func (r BusRoutes) RouteOf(busID string) (string, bool) {
+	id, ok := r[busID]
+	return id, ok
+}
+
+func (r BusRoutes) BusIDs() []string {
+	ids := make([]string, 0, len(r))
+	for id := range r {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// routeLookups returns the route found by the first lookup knowing the bus.
+type routeLookups []RouteLookup
+
+func (ls routeLookups) RouteOf(busID string) (string, bool) {
+	for _, l := range ls {
+		if id, ok := l.RouteOf(busID); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// allows report whether the bus is covered by the permission,
+// the route of the bus is only looked up when r is not nil.
+func (p *Permission) allows(busID string, r RouteLookup) bool {
+	for _, id := range p.BusIDs {
+		if id == busID {
+			return true
+		}
+	}
+
+	if r == nil || len(p.RouteIDs) == 0 {
+		return false
+	}
+	routeID, ok := r.RouteOf(busID)
+	if !ok {
+		return false
+	}
+	for _, id := range p.RouteIDs {
+		if id == routeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize check the customer is allowed to follow every bus.
+func (t *Tracker) Authorize(c Customer, busIDs []string) error {
+	if c.Permission == nil {
+		return nil
+	}
+
+	r := t.routes()
+	for _, id := range busIDs {
+		if !c.Permission.allows(id, r) {
+			return ErrNotAllowed
+		}
+	}
+	return nil
+}
+
+// allowed returns the buses the customer is allowed to follow.
+func (t *Tracker) allowed(c Customer, busIDs []string) []string {
+	if c.Permission == nil {
+		return busIDs
+	}
+
+	r := t.routes()
+	var allowed []string
+	for _, id := range busIDs {
+		if c.Permission.allows(id, r) {
+			allowed = append(allowed, id)
+		}
+	}
+	return allowed
+}
+
+// routes returns the lookup of the route of a bus, the route of its trip in progress comes first.
+func (t *Tracker) routes() RouteLookup {
+	if t.r == nil {
+		return t.t
+	}
+	return routeLookups{t.t, t.r}
+}
+
+// RouteBuses returns the buses currently serving one of the routes ordered by id.
+// The buses looked at are the ones on a trip, the ones with a cached location
+// and the ones listed by the route lookup when it is a BusLister.
+func (t *Tracker) RouteBuses(routeIDs []string) []string {
+	want := make(map[string]struct{}, len(routeIDs))
+	for _, id := range routeIDs {
+		want[id] = struct{}{}
+	}
+
+	candidates := t.t.busIDs()
+	if t.c != nil {
+		for _, l := range t.c.all() {
+			candidates = append(candidates, l.Bus.ID)
+		}
+	}
+	if bl, ok := t.r.(BusLister); ok {
+		candidates = append(candidates, bl.BusIDs()...)
+	}
+
+	r := t.routes()
+	seen := make(map[string]struct{}, len(candidates))
+	var buses []string
+	for _, id := range candidates {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if routeID, ok := r.RouteOf(id); ok {
+			if _, ok := want[routeID]; ok {
+				buses = append(buses, id)
+			}
+		}
+	}
+	sort.Strings(buses)
+	return buses
+}
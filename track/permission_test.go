@@ -0,0 +1,82 @@
+package track
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithRouteLookup(BusRoutes{"2": "r1", "3": "r2"}))
+	c := Customer{ID: "1", Permission: &Permission{BusIDs: []string{"1"}, RouteIDs: []string{"r1"}}}
+
+	assert.NoError(t, tracker.Authorize(c, []string{"1", "2"}))
+	assert.ErrorIs(t, tracker.Authorize(c, []string{"1", "3"}), ErrNotAllowed)
+	assert.ErrorIs(t, tracker.Authorize(c, []string{"4"}), ErrNotAllowed)
+
+	// customer without permission can follow any bus.
+	assert.NoError(t, tracker.Authorize(Customer{ID: "2"}, []string{"3", "4"}))
+
+	l := make(chan Update)
+	_, err := tracker.Register(c, Subscription{BusIDs: []string{"3"}}, l)
+	assert.ErrorIs(t, err, ErrNotAllowed)
+
+	hd, err := tracker.Register(c, Subscription{BusIDs: []string{"1"}}, l)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, tracker.Subscribe(hd, []string{"3"}), ErrNotAllowed)
+	assert.NoError(t, tracker.Subscribe(hd, []string{"2"}))
+	tracker.Unregister(hd)
+
+	// the route of the trip in progress comes before the assigned route.
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "3"}, RouteID: "r1", Status: TripStarted})
+	assert.NoError(t, tracker.Authorize(c, []string{"3"}))
+}
+
+func TestRouteBuses(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(0), WithRouteLookup(BusRoutes{"1": "r1", "2": "r1", "3": "r2"}))
+
+	// bus 2 is on a trip of another route, bus 4 is only known by its trip.
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "2"}, RouteID: "r2", Status: TripStarted})
+	tracker.ReceiveTrip(Trip{ID: "t2", Bus: Bus{ID: "4"}, RouteID: "r1", Status: TripStarted})
+	tracker.Receive(Location{Bus: Bus{ID: "5"}})
+
+	assert.Equal(t, []string{"1", "4"}, tracker.RouteBuses([]string{"r1"}))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, tracker.RouteBuses([]string{"r1", "r2"}))
+	assert.Empty(t, tracker.RouteBuses([]string{"r3"}))
+}
+
+func TestSubscribeRoutes(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithRouteLookup(BusRoutes{"1": "r1", "2": "r1"}))
+	c := Customer{ID: "1", Permission: &Permission{BusIDs: []string{"1", "5", "9"}}}
+
+	l := make(chan Update)
+	hd, err := tracker.Register(c, Subscription{BusIDs: []string{"9"}}, l)
+	require.NoError(t, err)
+
+	// bus 2 is on the route but not allowed.
+	busIDs, err := tracker.SubscribeRoutes(hd, []string{"r1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, busIDs)
+
+	// bus 5 joins the route and is followed from its first update, bus 6 is not allowed.
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "5"}, RouteID: "r1", Status: TripStarted})
+	tracker.ReceiveTrip(Trip{ID: "t2", Bus: Bus{ID: "6"}, RouteID: "r1", Status: TripStarted})
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "5"}})
+	assert.Equal(t, "t1", (<-l).Trip.ID)
+	assert.Equal(t, float64(1), (<-l).Location.Lat)
+
+	busIDs, err = tracker.UnsubscribeRoutes(hd, []string{"r1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "5", "6"}, busIDs)
+
+	// bus 1 left with the route, and the route is no longer followed.
+	tracker.Receive(Location{Lat: 2, Bus: Bus{ID: "1"}})
+	tracker.Receive(Location{Lat: 3, Bus: Bus{ID: "9"}})
+	assert.Equal(t, float64(3), (<-l).Location.Lat)
+
+	tracker.Unregister(hd)
+	_, err = tracker.SubscribeRoutes(hd, []string{"r1"})
+	assert.ErrorIs(t, err, ErrUnknownHandle)
+	assertEmptyHub(t, tracker.h)
+}
@@ -2,14 +2,21 @@ package track
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrBuffered returned by Sender when the location is accepted but kept
+// to be delivered later, because the broker is not available right now.
+var ErrBuffered = errors.New("location buffered for later delivery")
+
 // Customer denotes the customers object.
-// For now, we will just need the ID of it,
-// in next iteration we probably need to adjust it for better representing customer
 type Customer struct {
 	ID string
+	// Tenant is the organization the customer belongs to, it is empty for anonymous customer.
+	Tenant string
+	// Permission restrict the buses the customer can follow, nil means any bus.
+	Permission *Permission
 }
 
 // Location denotes the location object.
@@ -17,51 +24,380 @@ type Location struct {
 	Long      float64
 	Lat       float64
 	Bus       Bus
+	Driver    Driver
 	Timestamp time.Time
+	// Accuracy is the horizontal accuracy in meter, nil when unknown.
+	Accuracy *float64
+	// Altitude is the altitude above sea level in meter, nil when unknown.
+	Altitude *float64
+	// Speed is the ground speed in meter per second, nil when unknown.
+	Speed *float64
+	// Bearing is the direction of travel in degree clockwise from true north, nil when unknown.
+	Bearing *float64
+	// Satellites is the number of satellites used for the fix, nil when unknown.
+	Satellites *int
+	// Source is how the position is obtained, empty when unknown.
+	Source Source
+	// Seq is the sequence number given by the driver app, increasing for every location of the bus.
+	// Zero means the location has no sequence number.
+	Seq uint64
+	// TripID is the trip the bus is on when the location is sent, empty when the bus is not on a trip.
+	TripID string
+	// Route is the route the bus is serving, set on receive when it is known.
+	// Only its ID is set unless the enricher is enabled.
+	Route *Route
+	// NextStop is the next stop of the bus on its route, set by the enricher on receive.
+	NextStop *Stop
+}
+
+// Route denotes the route a bus is serving.
+type Route struct {
+	ID        string
+	ShortName string
+	LongName  string
+}
+
+// Stop denotes a stop of a route.
+type Stop struct {
+	ID   string
+	Name string
+	Lat  float64
+	Long float64
+}
+
+// Enricher add information about the route of the bus to the received location before it is broadcast.
+// The location given to the enricher has its Route ID set when the route of the bus is known.
+type Enricher interface {
+	Enrich(l Location) Location
+}
+
+// Update is delivered to the subscribers of a bus, it carries either a location or a trip event of the bus.
+type Update struct {
+	Location *Location
+	Trip     *Trip
 }
 
+// BusID returns the bus the update is about.
+func (u Update) BusID() string {
+	if u.Trip != nil {
+		return u.Trip.Bus.ID
+	}
+	return u.Location.Bus.ID
+}
+
+// Source denotes how the position of the location is obtained.
+type Source string
+
+const (
+	SourceGPS     Source = "gps"
+	SourceNetwork Source = "network"
+	SourceManual  Source = "manual"
+)
+
 // Bus denotes the bus object
 type Bus struct {
 	ID string
 }
 
+// Driver denotes the driver who sent the location, it is empty when the driver is not authenticated.
+type Driver struct {
+	ID string
+}
+
+// Handle identifies a single registered connection of a customer.
+// A customer can have many connections at the same time, each with their own Handle.
+type Handle struct {
+	id       uint64
+	Customer Customer
+}
+
+// Subscription denotes the set of buses a customer want to follow.
+// Since is the timestamp of the last location the client has of every bus, only the latest location
+// newer than it is sent on register, so a client resuming its subscription doesn't receive the location it already has.
+type Subscription struct {
+	BusIDs []string
+	Since  map[string]time.Time
+}
+
+// busIDs returns the unique non empty bus ids of the subscription.
+func (s Subscription) busIDs() []string {
+	seen := make(map[string]struct{}, len(s.BusIDs))
+	ids := make([]string, 0, len(s.BusIDs))
+	for _, id := range s.BusIDs {
+		if _, ok := seen[id]; ok || id == "" {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // Sender will be the contract to send location
 type Sender interface {
 	Send(ctx context.Context, l Location) error
 }
 
+// BatchSender is implemented by Sender able to send many location in a single call.
+// The order of location of the same bus must be kept.
+type BatchSender interface {
+	SendBatch(ctx context.Context, locs []Location) error
+}
+
 // Tracker will responsible for the tracking location including receiving location and sending location
 type Tracker struct {
 	h *hub
+	c *cache
 	s Sender
+	r RouteLookup
+	v *validator
+	o *orderer
+	f *filterChain
+	d Sender
+	e Enricher
+
+	t  *trips
+	ts TripSender
 }
 
-// Send will send the location and bus information
+// Validate check the location sent by the driver, it is shared by every transport accepting location.
+// A *ValidationError describing every invalid field is returned.
+func (t *Tracker) Validate(l Location) error {
+	return t.v.validate(l)
+}
+
+// Send will send the location and bus information.
+// ErrBuffered is returned when the sender keep the location to deliver it later.
 func (t *Tracker) Send(ctx context.Context, l Location) error {
 	return t.s.Send(ctx, l)
 }
 
-// Receive will be receiving the location and send that location to all registered customer.
+// SendBatch will send the locations in order, in a single call when the sender support it.
+// ErrBuffered is returned when at least one location is kept to be delivered later.
+func (t *Tracker) SendBatch(ctx context.Context, locs []Location) error {
+	return SendBatch(ctx, t.s, locs)
+}
+
+// SendBatch send the locations with s, one by one when s is not a BatchSender.
+func SendBatch(ctx context.Context, s Sender, locs []Location) error {
+	if bs, ok := s.(BatchSender); ok {
+		return bs.SendBatch(ctx, locs)
+	}
+
+	var buffered bool
+	for _, l := range locs {
+		err := s.Send(ctx, l)
+		if errors.Is(err, ErrBuffered) {
+			buffered = true
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if buffered {
+		return ErrBuffered
+	}
+	return nil
+}
+
+// Receive will be receiving the location and send that location to all customer subscribed to the bus.
+// When ordering is enabled, a location that is a duplicate or older than the newest location of the bus is dropped and counted.
+// The location is then given to the filters, a rejected location is counted and sent to the diagnostics sender.
+// The location is also kept as the latest known location of the bus when the cache is enabled,
+// and its missing speed and bearing are derived from the previous location of the bus.
+// A location sent without trip is stamped with the trip in progress of the bus and its route, when they are known,
+// and is given to the enricher when it is enabled. The bus is added to the connections following its route.
 func (t *Tracker) Receive(l Location) {
-	t.h.receive(l)
+	if t.o != nil && !t.o.accept(l) {
+		return
+	}
+
+	if t.f != nil {
+		var err error
+		if l, err = t.f.apply(l); err != nil {
+			t.reject(l, err)
+			return
+		}
+	}
+
+	t.stamp(&l)
+	if t.c != nil {
+		if prev, ok := t.c.get(l.Bus.ID); ok {
+			l = derive(prev, l)
+		}
+	}
+
+	l.Route, l.NextStop = nil, nil
+	if routeID, ok := t.routes().RouteOf(l.Bus.ID); ok {
+		l.Route = &Route{ID: routeID}
+	}
+	if t.e != nil {
+		l = t.e.Enrich(l)
+	}
+
+	if t.c != nil {
+		t.c.set(l)
+	}
+	if l.Route != nil {
+		t.join(l.Bus.ID, l.Route.ID)
+	}
+	t.h.receive(Update{Location: &l})
+}
+
+func (t *Tracker) reject(l Location, err error) {
+	stats.Add("rejected", 1)
+	var re *RejectError
+	if errors.As(err, &re) {
+		stats.Add("rejected_"+re.Filter, 1)
+	}
+
+	if t.d == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+	if err := t.d.Send(ctx, l); err != nil && !errors.Is(err, ErrBuffered) {
+		stats.Add("diagnostics_failures", 1)
+	}
+}
+
+// LastLocation returns the latest known location of the bus.
+// It returns false when the bus location is unknown, expired or the cache is not enabled.
+func (t *Tracker) LastLocation(busID string) (Location, bool) {
+	if t.c == nil {
+		return Location{}, false
+	}
+	return t.c.get(busID)
+}
+
+// LastLocations returns the latest known location of every bus ordered by bus id,
+// it is empty when the cache is not enabled.
+func (t *Tracker) LastLocations() []Location {
+	if t.c == nil {
+		return nil
+	}
+	return t.c.all()
 }
 
 // Register will register the client to the hub.
-// If client want to receive message they need to register the customer and location channel.
-func (t *Tracker) Register(c Customer, l chan Location) {
-	t.h.register(c, l)
+// If client want to receive message they need to register the customer, the buses they subscribe to and location channel.
+// The returned Handle identifies this connection and is used to unregister it.
+// The trip in progress of the subscribed buses is sent right away,
+// followed by their latest location when the cache is enabled.
+// ErrNotAllowed is returned when the customer is not allowed to follow one of the buses.
+func (t *Tracker) Register(c Customer, s Subscription, u chan Update) (Handle, error) {
+	if err := t.Authorize(c, s.BusIDs); err != nil {
+		return Handle{}, err
+	}
+	return t.h.register(c, s, u, func(busIDs []string) []Update {
+		return t.snapshot(busIDs, s.Since)
+	})
+}
+
+// snapshot returns the trip in progress and the latest location of every bus,
+// only the location newer than the since of its bus when it is set.
+func (t *Tracker) snapshot(busIDs []string, since map[string]time.Time) []Update {
+	var updates []Update
+	for _, id := range busIDs {
+		if tr, ok := t.t.get(id); ok {
+			updates = append(updates, Update{Trip: &tr})
+		}
+		if t.c == nil {
+			continue
+		}
+		if l, ok := t.c.get(id); ok && (since[id].IsZero() || l.Timestamp.After(since[id])) {
+			updates = append(updates, Update{Location: &l})
+		}
+	}
+	return updates
+}
+
+// Subscribe add the buses to a registered connection,
+// the trip and the latest location of the newly added buses is sent right away.
+// ErrUnknownHandle is returned when the connection is no longer registered,
+// and ErrNotAllowed when the customer is not allowed to follow one of the buses.
+func (t *Tracker) Subscribe(h Handle, busIDs []string) error {
+	if err := t.Authorize(h.Customer, busIDs); err != nil {
+		return err
+	}
+	return t.h.subscribe(h, busIDs, func(busIDs []string) []Update {
+		return t.snapshot(busIDs, nil)
+	})
+}
+
+// SubscribeRoutes add the routes to a registered connection, it follows the buses serving the routes now
+// and every bus joining one of them later, until the routes are unsubscribed.
+// The bus the customer is not allowed to follow is skipped. It returns the buses serving the routes now,
+// their trip and latest location is sent right away.
+// ErrUnknownHandle is returned when the connection is no longer registered.
+func (t *Tracker) SubscribeRoutes(h Handle, routeIDs []string) ([]string, error) {
+	// the routes are indexed first, so a bus joining them meanwhile is added by join.
+	if err := t.h.subscribeRoutes(h, routeIDs); err != nil {
+		return nil, err
+	}
+
+	busIDs := t.allowed(h.Customer, t.RouteBuses(routeIDs))
+	err := t.h.subscribe(h, busIDs, func(busIDs []string) []Update {
+		return t.snapshot(busIDs, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return busIDs, nil
+}
+
+// UnsubscribeRoutes remove the routes from a registered connection, and the buses serving them now.
+// It returns the buses serving the routes now.
+// ErrUnknownHandle is returned when the connection is no longer registered.
+func (t *Tracker) UnsubscribeRoutes(h Handle, routeIDs []string) ([]string, error) {
+	if err := t.h.unsubscribeRoutes(h, routeIDs); err != nil {
+		return nil, err
+	}
+
+	busIDs := t.RouteBuses(routeIDs)
+	if err := t.h.unsubscribe(h, busIDs); err != nil {
+		return nil, err
+	}
+	return busIDs, nil
+}
+
+// join add the bus to the connections following its route, when their customer is allowed to follow it.
+// It is called before the update of the bus is given to the hub, so the update is delivered to them.
+func (t *Tracker) join(busID, routeID string) {
+	for _, h := range t.h.routeSubscribers(routeID, busID) {
+		if t.Authorize(h.Customer, []string{busID}) != nil {
+			continue
+		}
+		if err := t.h.subscribe(h, []string{busID}, nil); err == nil {
+			stats.Add("route_joins", 1)
+		}
+	}
+}
+
+// Unsubscribe remove the buses from a registered connection.
+// ErrUnknownHandle is returned when the connection is no longer registered.
+func (t *Tracker) Unsubscribe(h Handle, busIDs []string) error {
+	return t.h.unsubscribe(h, busIDs)
+}
+
+// Unregister will remove the connection from the Hub and also close its registered channel
+func (t *Tracker) Unregister(h Handle) {
+	t.h.unregister(h)
 }
 
-// Unregister will remove the client from the Hub and also close their registered channel
-func (t *Tracker) Unregister(c Customer) {
-	t.h.unregister(c)
+// Dropped returns how many location has been dropped for the customer because they could not keep up.
+func (t *Tracker) Dropped(c Customer) uint64 {
+	return t.h.dropped(c)
 }
 
 type opts func(*Tracker)
 
 // NewTracker will create new Tracker
 func NewTracker(opts ...opts) *Tracker {
-	t := Tracker{}
+	t := Tracker{v: newValidator(ValidationConfig{}), t: newTrips()}
 
 	for _, opt := range opts {
 		opt(&t)
@@ -71,17 +407,76 @@ func NewTracker(opts ...opts) *Tracker {
 }
 
 // WithHub will be initiate hub for receiving and broadcasting message
-func WithHub() opts {
+func WithHub(cfg HubConfig) opts {
 	return func(t *Tracker) {
-		t.h = &hub{
-			customers: make(map[string]chan Location),
+		t.h = newHub(cfg)
+	}
+}
+
+// WithCache will keep the latest location of every bus for ttl,
+// zero ttl means the location never expires.
+func WithCache(ttl time.Duration) opts {
+	return func(t *Tracker) {
+		t.c = newCache(ttl)
+	}
+}
+
+// WithRouteLookup will be used to find the route of a bus that is not on a trip,
+// when the customer permission is given by routes and to set the route of the received location.
+func WithRouteLookup(r RouteLookup) opts {
+	return func(t *Tracker) {
+		t.r = r
+	}
+}
+
+// WithValidation will reject location with timestamp too far from the server clock.
+func WithValidation(cfg ValidationConfig) opts {
+	return func(t *Tracker) {
+		t.v = newValidator(cfg)
+	}
+}
+
+// WithOrdering will drop the duplicated location and the location received after a newer location of the same bus.
+func WithOrdering() opts {
+	return func(t *Tracker) {
+		t.o = newOrderer()
+	}
+}
+
+// WithFilters will run the filters in order on every received location before it is broadcast.
+func WithFilters(filters ...Filter) opts {
+	return func(t *Tracker) {
+		if len(filters) > 0 {
+			t.f = newFilterChain(filters)
 		}
 	}
 }
 
+// WithDiagnostics will send the location rejected by the filters to s.
+func WithDiagnostics(s Sender) opts {
+	return func(t *Tracker) {
+		t.d = s
+	}
+}
+
+// WithEnricher will add the route and the next stop of the bus to the received location with e.
+func WithEnricher(e Enricher) opts {
+	return func(t *Tracker) {
+		t.e = e
+	}
+}
+
 // WithSender will assign sender to tracker and activate Tracker ability to send message
 func WithSender(s Sender) opts {
 	return func(t *Tracker) {
 		t.s = s
 	}
 }
+
+// WithTripSender will send the trip event with s, instead of the sender.
+// It is needed when the sender is not a TripSender.
+func WithTripSender(s TripSender) opts {
+	return func(t *Tracker) {
+		t.ts = s
+	}
+}
@@ -0,0 +1,216 @@
+package track
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TripStatus denotes the latest event of the trip.
+type TripStatus string
+
+const (
+	TripStarted TripStatus = "started"
+	TripPaused  TripStatus = "paused"
+	TripResumed TripStatus = "resumed"
+	TripEnded   TripStatus = "ended"
+)
+
+// Trip denotes a bus serving a route, from its start to its end.
+// A trip event is sent with the whole trip every time its status change.
+type Trip struct {
+	ID        string
+	Bus       Bus
+	RouteID   string
+	Driver    Driver
+	Status    TripStatus
+	Timestamp time.Time
+}
+
+// TripSender will be the contract to send trip event
+type TripSender interface {
+	SendTrip(ctx context.Context, t Trip) error
+}
+
+// next report whether the trip can go to status from its current status.
+func (t Trip) next(status TripStatus) bool {
+	switch status {
+	case TripPaused:
+		return t.Status == TripStarted || t.Status == TripResumed
+	case TripResumed:
+		return t.Status == TripPaused
+	case TripEnded:
+		return t.Status != TripEnded
+	default:
+		return false
+	}
+}
+
+// trips keeps the trip in progress of every bus, an ended trip is forgotten.
+type trips struct {
+	mu    sync.RWMutex
+	buses map[string]Trip // bus id -> trip
+}
+
+func newTrips() *trips {
+	return &trips{buses: make(map[string]Trip)}
+}
+
+func (t *trips) get(busID string) (Trip, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	tr, ok := t.buses[busID]
+	return tr, ok
+}
+
+// apply record the trip event and returns it with the route and the driver of the trip in progress.
+// A started event replaces the trip in progress of the bus. Any other event is only applied
+// to the trip in progress with the same id when its status allows it, an ended event of a bus
+// without trip in progress is applied so the rider still learn the bus is out of service.
+// The caller must hold t.mu.
+func (t *trips) apply(tr Trip) (Trip, bool) {
+	cur, ok := t.buses[tr.Bus.ID]
+	switch {
+	case tr.Status == TripStarted:
+	case ok && cur.ID == tr.ID && cur.next(tr.Status):
+		tr.RouteID = cur.RouteID
+		if tr.Driver.ID == "" {
+			tr.Driver = cur.Driver
+		}
+	case !ok && tr.Status == TripEnded:
+		return tr, true
+	default:
+		return Trip{}, false
+	}
+
+	if tr.Status == TripEnded {
+		delete(t.buses, tr.Bus.ID)
+		return tr, true
+	}
+	t.buses[tr.Bus.ID] = tr
+	return tr, true
+}
+
+// busIDs returns the buses with a trip in progress.
+func (t *trips) busIDs() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	ids := make([]string, 0, len(t.buses))
+	for id := range t.buses {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// RouteOf returns the route of the trip in progress of the bus.
+func (t *trips) RouteOf(busID string) (string, bool) {
+	tr, ok := t.get(busID)
+	return tr.RouteID, ok
+}
+
+// StartTrip send the started event of a trip of the bus on the route.
+// The trip id is kept when the driver gives one, so the driver can retry the start and any instance can update the trip,
+// the status and the timestamp are set by the tracker. Nothing is kept by the sending tracker,
+// the tracker receiving the event is the source of truth of the trip in progress, see ReceiveTrip.
+// The trip is started when the sender keep the event to deliver it later.
+// A *ValidationError is returned when the bus or the route is missing.
+func (t *Tracker) StartTrip(ctx context.Context, tr Trip) (Trip, error) {
+	var verr ValidationError
+	if tr.Bus.ID == "" {
+		verr.Add("bus_id", "is required")
+	}
+	if tr.RouteID == "" {
+		verr.Add("route_id", "is required")
+	}
+	if err := verr.Err(); err != nil {
+		return Trip{}, err
+	}
+
+	if tr.ID == "" {
+		tr.ID = uuid.NewString()
+	}
+	tr.Status = TripStarted
+	tr.Timestamp = time.Now()
+	if err := t.sendTrip(ctx, tr); err != nil && !errors.Is(err, ErrBuffered) {
+		return Trip{}, err
+	}
+	return tr, nil
+}
+
+// UpdateTrip send the paused, resumed or ended event of the trip of the bus, tr carries the trip id, the bus and the new status.
+// The event is applied by the tracker receiving it only when the status of the trip allows it.
+// The trip is updated when the sender keep the event to deliver it later.
+// A *ValidationError is returned when the trip id or the bus is missing, or the status is not an update.
+func (t *Tracker) UpdateTrip(ctx context.Context, tr Trip) (Trip, error) {
+	var verr ValidationError
+	if tr.ID == "" {
+		verr.Add("id", "is required")
+	}
+	if tr.Bus.ID == "" {
+		verr.Add("bus_id", "is required")
+	}
+	if tr.Status != TripPaused && tr.Status != TripResumed && tr.Status != TripEnded {
+		verr.Add("status", "must be paused, resumed or ended")
+	}
+	if err := verr.Err(); err != nil {
+		return Trip{}, err
+	}
+
+	tr.Timestamp = time.Now()
+	if err := t.sendTrip(ctx, tr); err != nil && !errors.Is(err, ErrBuffered) {
+		return Trip{}, err
+	}
+	return tr, nil
+}
+
+// ActiveTrip returns the trip in progress of the bus.
+func (t *Tracker) ActiveTrip(busID string) (Trip, bool) {
+	return t.t.get(busID)
+}
+
+func (t *Tracker) sendTrip(ctx context.Context, tr Trip) error {
+	ts, ok := t.s.(TripSender)
+	if t.ts != nil {
+		ts, ok = t.ts, true
+	}
+	if !ok {
+		return errors.New("trip sender is not configured")
+	}
+	return ts.SendTrip(ctx, tr)
+}
+
+// ReceiveTrip will be receiving the trip event and send it to all customer subscribed to the bus.
+// An event that doesn't apply to the trip in progress of the bus is counted and dropped.
+// The bus of a trip that is not ended is added to the connections following its route, see SubscribeRoutes.
+// When the trip is ended, the latest location of the bus is removed from the cache,
+// so the bus is not shown at its last position while it is not in service.
+func (t *Tracker) ReceiveTrip(tr Trip) {
+	t.t.mu.Lock()
+	tr, ok := t.t.apply(tr)
+	t.t.mu.Unlock()
+
+	if !ok {
+		stats.Add("trips_rejected", 1)
+		return
+	}
+	if tr.Status == TripEnded && t.c != nil {
+		t.c.delete(tr.Bus.ID)
+	}
+	if tr.Status != TripEnded && tr.RouteID != "" {
+		t.join(tr.Bus.ID, tr.RouteID)
+	}
+	t.h.receive(Update{Trip: &tr})
+}
+
+// stamp set the trip in progress of the bus to the location, the trip sent by the device is never trusted.
+func (t *Tracker) stamp(l *Location) {
+	l.TripID = ""
+	if tr, ok := t.t.get(l.Bus.ID); ok {
+		l.TripID = tr.ID
+	}
+}
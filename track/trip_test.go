@@ -0,0 +1,168 @@
+package track
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tripRecorder struct {
+	locations []Location
+	trips     []Trip
+	err       error
+}
+
+func (r *tripRecorder) Send(_ context.Context, l Location) error {
+	r.locations = append(r.locations, l)
+	return nil
+}
+
+func (r *tripRecorder) SendTrip(_ context.Context, t Trip) error {
+	r.trips = append(r.trips, t)
+	return r.err
+}
+
+func TestTripLifecycle(t *testing.T) {
+	ctx := context.Background()
+	rec := &tripRecorder{}
+	driver := NewTracker(WithSender(rec))
+
+	_, err := driver.StartTrip(ctx, Trip{})
+	var ve *ValidationError
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, []FieldError{{"bus_id", "is required"}, {"route_id", "is required"}}, ve.Fields)
+
+	trip, err := driver.StartTrip(ctx, Trip{Bus: Bus{ID: "1"}, RouteID: "r1", Driver: Driver{ID: "d1"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, trip.ID)
+	assert.Equal(t, TripStarted, trip.Status)
+
+	// the id given by the driver is kept.
+	retried, err := driver.StartTrip(ctx, Trip{ID: trip.ID, Bus: Bus{ID: "1"}, RouteID: "r1"})
+	require.NoError(t, err)
+	assert.Equal(t, trip.ID, retried.ID)
+
+	_, err = driver.UpdateTrip(ctx, Trip{ID: trip.ID, Status: TripStarted})
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, []FieldError{{"bus_id", "is required"}, {"status", "must be paused, resumed or ended"}}, ve.Fields)
+
+	for _, status := range []TripStatus{TripPaused, TripResumed, TripEnded} {
+		got, err := driver.UpdateTrip(ctx, Trip{ID: trip.ID, Bus: Bus{ID: "1"}, Status: status})
+		require.NoError(t, err)
+		assert.Equal(t, status, got.Status)
+	}
+
+	// the sending tracker keeps no trip, the location is sent as is.
+	_, ok := driver.ActiveTrip("1")
+	assert.False(t, ok)
+	assert.NoError(t, driver.Send(ctx, Location{Bus: Bus{ID: "1"}}))
+	assert.Empty(t, rec.locations[0].TripID)
+
+	assert.Equal(t, []TripStatus{TripStarted, TripStarted, TripPaused, TripResumed, TripEnded},
+		[]TripStatus{rec.trips[0].Status, rec.trips[1].Status, rec.trips[2].Status, rec.trips[3].Status, rec.trips[4].Status})
+}
+
+func TestTripBuffered(t *testing.T) {
+	ctx := context.Background()
+	driver := NewTracker(WithSender(&tripRecorder{err: ErrBuffered}))
+
+	// the event is kept by the sender, the trip is started anyway.
+	trip, err := driver.StartTrip(ctx, Trip{Bus: Bus{ID: "1"}, RouteID: "r1"})
+	require.NoError(t, err)
+	_, err = driver.UpdateTrip(ctx, Trip{ID: trip.ID, Bus: Bus{ID: "1"}, Status: TripEnded})
+	require.NoError(t, err)
+}
+
+func TestReceiveTripTransition(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}))
+	u := make(chan Update)
+	hd, err := tracker.Register(Customer{ID: "1"}, Subscription{BusIDs: []string{"1"}}, u)
+	require.NoError(t, err)
+	before := counter("trips_rejected")
+
+	// the update only carries the id, the bus and the status, the route and the driver come from the trip in progress.
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "1"}, RouteID: "r1", Driver: Driver{ID: "d1"}, Status: TripStarted})
+	assert.Equal(t, TripStarted, (<-u).Trip.Status)
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "1"}, Status: TripResumed})
+	tracker.ReceiveTrip(Trip{ID: "t2", Bus: Bus{ID: "1"}, Status: TripPaused})
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "1"}, Status: TripPaused})
+	paused := (<-u).Trip
+	assert.Equal(t, Trip{ID: "t1", Bus: Bus{ID: "1"}, RouteID: "r1", Driver: Driver{ID: "d1"}, Status: TripPaused}, *paused)
+	assert.Equal(t, before+2, counter("trips_rejected"))
+
+	// a new start replaces the trip in progress of the bus.
+	tracker.ReceiveTrip(Trip{ID: "t2", Bus: Bus{ID: "1"}, RouteID: "r2", Status: TripStarted})
+	assert.Equal(t, "t2", (<-u).Trip.ID)
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "1"}, Status: TripEnded})
+	tracker.ReceiveTrip(Trip{ID: "t2", Bus: Bus{ID: "1"}, Status: TripEnded})
+	assert.Equal(t, Trip{ID: "t2", Bus: Bus{ID: "1"}, RouteID: "r2", Status: TripEnded}, *(<-u).Trip)
+	_, ok := tracker.ActiveTrip("1")
+	assert.False(t, ok)
+
+	// the end of a trip lost by a restart still reach the rider.
+	tracker.ReceiveTrip(Trip{ID: "t3", Bus: Bus{ID: "1"}, Status: TripEnded})
+	assert.Equal(t, "t3", (<-u).Trip.ID)
+	assert.Equal(t, before+3, counter("trips_rejected"))
+
+	tracker.Unregister(hd)
+}
+
+func TestReceiveTrip(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(0))
+	trip := Trip{ID: "t1", Bus: Bus{ID: "1"}, RouteID: "r1", Status: TripStarted}
+	tracker.ReceiveTrip(trip)
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "1"}})
+
+	// the rider allowed on the route can follow the bus while it is on the trip.
+	c := Customer{ID: "1", Permission: &Permission{RouteIDs: []string{"r1"}}}
+	u := make(chan Update)
+	hd, err := tracker.Register(c, Subscription{BusIDs: []string{"1"}}, u)
+	require.NoError(t, err)
+
+	assert.Equal(t, trip, *(<-u).Trip)
+	l := (<-u).Location
+	assert.Equal(t, "t1", l.TripID)
+	assert.Equal(t, &Route{ID: "r1"}, l.Route)
+
+	trip.Status = TripEnded
+	tracker.ReceiveTrip(trip)
+	assert.Equal(t, TripEnded, (<-u).Trip.Status)
+
+	_, ok := tracker.LastLocation("1")
+	assert.False(t, ok)
+	assert.ErrorIs(t, tracker.Authorize(c, []string{"1"}), ErrNotAllowed)
+
+	tracker.Unregister(hd)
+}
+
+type enricherFunc func(l Location) Location
+
+func (f enricherFunc) Enrich(l Location) Location {
+	return f(l)
+}
+
+func TestReceiveEnrich(t *testing.T) {
+	tracker := NewTracker(WithHub(HubConfig{}), WithCache(0), WithEnricher(enricherFunc(func(l Location) Location {
+		if l.Route != nil {
+			l.Route.ShortName = "R" + l.Route.ID
+			l.NextStop = &Stop{ID: "s1"}
+		}
+		return l
+	})))
+	tracker.ReceiveTrip(Trip{ID: "t1", Bus: Bus{ID: "1"}, RouteID: "1", Status: TripStarted})
+
+	// the route of the bus is given by its trip.
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "1"}})
+	l, ok := tracker.LastLocation("1")
+	require.True(t, ok)
+	assert.Equal(t, &Route{ID: "1", ShortName: "R1"}, l.Route)
+	assert.Equal(t, &Stop{ID: "s1"}, l.NextStop)
+
+	tracker.Receive(Location{Lat: 1, Bus: Bus{ID: "2"}})
+	l, ok = tracker.LastLocation("2")
+	require.True(t, ok)
+	assert.Nil(t, l.Route)
+	assert.Nil(t, l.NextStop)
+}
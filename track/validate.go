@@ -0,0 +1,131 @@
+package track
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	minAltitude = -1000.0
+	maxAltitude = 20000.0
+	// maxSpeed is the highest ground speed accepted, in meter per second.
+	maxSpeed = 100.0
+)
+
+// FieldError denotes why a field of the location is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when the location has one or more invalid fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return "invalid location: " + strings.Join(msgs, ", ")
+}
+
+// Add record an invalid field.
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns e when at least one field is invalid, or nil.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidationConfig denotes how far the location timestamp can be from the server clock.
+// Zero means the timestamp is not checked in that direction.
+type ValidationConfig struct {
+	MaxFutureSkew time.Duration
+	MaxPastSkew   time.Duration
+}
+
+// validator check the location sent by the driver before it is published.
+type validator struct {
+	cfg ValidationConfig
+	now func() time.Time
+}
+
+func newValidator(cfg ValidationConfig) *validator {
+	return &validator{cfg: cfg, now: time.Now}
+}
+
+// validate check the bus, the WGS84 coordinate, the timestamp and the optional field of the fix.
+func (v *validator) validate(l Location) error {
+	var e ValidationError
+
+	if l.Bus.ID == "" {
+		e.Add("bus_id", "is required")
+	}
+
+	latOK, longOK := inRange(l.Lat, 90), inRange(l.Long, 180)
+	switch {
+	// a latitude out of range with a longitude that would be a valid latitude
+	// is most likely sent in the wrong order.
+	case !latOK && inRange(l.Long, 90) && inRange(l.Lat, 180):
+		e.Add("lat", "must be between -90 and 90, lat and long look swapped")
+	case !latOK:
+		e.Add("lat", "must be between -90 and 90")
+	}
+	if !longOK {
+		e.Add("long", "must be between -180 and 180")
+	}
+
+	if l.Timestamp.IsZero() {
+		e.Add("timestamp", "is required")
+	} else {
+		now := v.now()
+		if v.cfg.MaxFutureSkew > 0 && l.Timestamp.After(now.Add(v.cfg.MaxFutureSkew)) {
+			e.Add("timestamp", fmt.Sprintf("must not be more than %s in the future", v.cfg.MaxFutureSkew))
+		}
+		if v.cfg.MaxPastSkew > 0 && l.Timestamp.Before(now.Add(-v.cfg.MaxPastSkew)) {
+			e.Add("timestamp", fmt.Sprintf("must not be more than %s in the past", v.cfg.MaxPastSkew))
+		}
+	}
+
+	if l.Accuracy != nil && (!finite(*l.Accuracy) || *l.Accuracy < 0) {
+		e.Add("accuracy", "must be a positive number of meter")
+	}
+	if l.Altitude != nil && (!finite(*l.Altitude) || *l.Altitude < minAltitude || *l.Altitude > maxAltitude) {
+		e.Add("altitude", fmt.Sprintf("must be between %g and %g meter", minAltitude, maxAltitude))
+	}
+
+	if l.Speed != nil && (!finite(*l.Speed) || *l.Speed < 0 || *l.Speed > maxSpeed) {
+		e.Add("speed", fmt.Sprintf("must be between 0 and %g meter per second", maxSpeed))
+	}
+	if l.Bearing != nil && (!finite(*l.Bearing) || *l.Bearing < 0 || *l.Bearing >= 360) {
+		e.Add("bearing", "must be between 0 and 360 degree")
+	}
+	if l.Satellites != nil && *l.Satellites < 0 {
+		e.Add("satellites", "must not be negative")
+	}
+	switch l.Source {
+	case "", SourceGPS, SourceNetwork, SourceManual:
+	default:
+		e.Add("source", fmt.Sprintf("must be one of %s, %s or %s", SourceGPS, SourceNetwork, SourceManual))
+	}
+
+	return e.Err()
+}
+
+// inRange report whether f is a finite number between -max and max.
+func inRange(f, max float64) bool {
+	return finite(f) && f >= -max && f <= max
+}
+
+func finite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
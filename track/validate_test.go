@@ -0,0 +1,61 @@
+package track
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := newValidator(ValidationConfig{MaxFutureSkew: time.Minute, MaxPastSkew: time.Hour})
+	v.now = func() time.Time { return now }
+
+	float := func(f float64) *float64 { return &f }
+	valid := Location{Lat: -6.1754, Long: 106.8272, Bus: Bus{ID: "1"}, Timestamp: now}
+
+	tests := []struct {
+		name   string
+		modify func(l *Location)
+		fields []FieldError
+	}{
+		{name: "valid", modify: func(l *Location) {}},
+		{name: "valid optional field", modify: func(l *Location) { l.Accuracy, l.Altitude = float(5), float(-10) }},
+		{name: "missing bus", modify: func(l *Location) { l.Bus.ID = "" }, fields: []FieldError{{"bus_id", "is required"}}},
+		{name: "lat out of range", modify: func(l *Location) { l.Lat = 900 }, fields: []FieldError{{"lat", "must be between -90 and 90"}}},
+		{name: "swapped", modify: func(l *Location) { l.Lat, l.Long = l.Long, l.Lat }, fields: []FieldError{{"lat", "must be between -90 and 90, lat and long look swapped"}}},
+		{name: "nan", modify: func(l *Location) { l.Long = math.NaN() }, fields: []FieldError{{"long", "must be between -180 and 180"}}},
+		{name: "future", modify: func(l *Location) { l.Timestamp = now.Add(2 * time.Minute) }, fields: []FieldError{{"timestamp", "must not be more than 1m0s in the future"}}},
+		{name: "past", modify: func(l *Location) { l.Timestamp = now.Add(-2 * time.Hour) }, fields: []FieldError{{"timestamp", "must not be more than 1h0m0s in the past"}}},
+		{name: "negative accuracy", modify: func(l *Location) { l.Accuracy = float(-1) }, fields: []FieldError{{"accuracy", "must be a positive number of meter"}}},
+		{name: "altitude", modify: func(l *Location) { l.Altitude = float(math.Inf(1)) }, fields: []FieldError{{"altitude", "must be between -1000 and 20000 meter"}}},
+		{name: "speed", modify: func(l *Location) { l.Speed = float(-1) }, fields: []FieldError{{"speed", "must be between 0 and 100 meter per second"}}},
+		{name: "bearing", modify: func(l *Location) { l.Bearing = float(360) }, fields: []FieldError{{"bearing", "must be between 0 and 360 degree"}}},
+		{name: "satellites", modify: func(l *Location) { n := -1; l.Satellites = &n }, fields: []FieldError{{"satellites", "must not be negative"}}},
+		{name: "source", modify: func(l *Location) { l.Source = "wifi" }, fields: []FieldError{{"source", "must be one of gps, network or manual"}}},
+		{
+			name:   "many",
+			modify: func(l *Location) { l.Bus.ID, l.Timestamp = "", time.Time{} },
+			fields: []FieldError{{"bus_id", "is required"}, {"timestamp", "is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := valid
+			tt.modify(&l)
+
+			err := v.validate(l)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var ve *ValidationError
+			assert.ErrorAs(t, err, &ve)
+			assert.Equal(t, tt.fields, ve.Fields)
+		})
+	}
+}